@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// PlayMode is what a Clip does after its last frame.
+type PlayMode int
+
+const (
+	// Start over from the first frame
+	PlayLoop PlayMode = iota
+
+	// Stop on the last frame, the sprite's OnClipEnd is called
+	PlayOnce
+
+	// Play backwards to the first frame, then forwards again, forever
+	PlayPingPong
+)
+
+func (m PlayMode) String() string {
+	var s string
+	switch m {
+	case PlayLoop:
+		s = "LOOP"
+	case PlayOnce:
+		s = "ONCE"
+	case PlayPingPong:
+		s = "PINGPONG"
+	}
+	return s
+}
+
+// Clip is a named animation, e.g. "idle" or "run-left", made of cells from a
+// SpriteSheet.
+type Clip struct {
+	Name string
+
+	// Sheet cell indexes, in play order
+	Frames []int
+
+	// How long each frame is shown, in ms. Frames without a duration use
+	// 1000 / FPS. A frame lasting 0 ms (or FPS <= 0) is never left, the clip
+	// stops there.
+	Durations []uint32
+	FPS       float64
+
+	Mode PlayMode
+
+	// Mirror the frames when drawing, so one set of frames can be used for
+	// both directions.
+	Flip sdl.RendererFlip
+}
+
+func NewClip(name string, frames []int, fps float64, mode PlayMode) *Clip {
+	return &Clip{
+		Name:   name,
+		Frames: frames,
+		FPS:    fps,
+		Mode:   mode,
+	}
+}
+
+// Mirrored is a copy of the clip, called name, that draws its frames
+// flipped. E.g. "run-left" from a sheet where the sprite runs right.
+func (c *Clip) Mirrored(name string, flip sdl.RendererFlip) *Clip {
+	mirrored := *c
+	mirrored.Name = name
+	mirrored.Flip = flip
+	return &mirrored
+}
+
+// FrameRange is start, start + 1, ... start + n - 1. Handy for clips that
+// use a run of cells.
+func FrameRange(start, n int) []int {
+	frames := make([]int, n)
+	for i := range frames {
+		frames[i] = start + i
+	}
+	return frames
+}
+
+// Duration is how long, in ms, frame i of the clip is shown. 0 is forever.
+func (c *Clip) Duration(i int) uint32 {
+	if i < len(c.Durations) {
+		return c.Durations[i]
+	}
+	if c.FPS <= 0 {
+		return 0
+	}
+	if c.FPS > 1000 {
+		// Faster than the ms clock goes
+		return 1
+	}
+	return uint32(1000.0 / c.FPS)
+}
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Assets loads textures once and shares them. Every Texture() call for a
+// path takes a reference and must be matched by a Release(). The texture is
+// destroyed when the last reference goes away, or by DestroyAll(), which
+// Window.Cleanup() calls.
+//
+// Paths are resolved against SearchPaths first, in order, then looked up in
+// FS. Set FS to an embed.FS to ship the images inside the binary:
+//
+//	//go:embed *.png
+//	var sprites embed.FS
+//
+//	w.Assets.FS = sprites
+//
+// Build with `-tags debug` to log textures still referenced at DestroyAll()
+// (leaks) and releases of textures that aren't loaded (double frees).
+type Assets struct {
+	// Directories searched for relative paths. Defaults to the working
+	// directory and the directory of the executable.
+	SearchPaths []string
+
+	// Embedded (or any other) files to fall back on when a path isn't found
+	// in SearchPaths.
+	FS fs.FS
+
+	// Development mode: Reload() picks up textures changed on disk.
+	HotReload bool
+
+	renderer *sdl.Renderer
+	textures map[string]*textureAsset
+	paths    map[*sdl.Texture]string
+
+	// Textures already destroyed, only tracked in debug builds
+	freed map[*sdl.Texture]string
+
+	// Last time Reload() looked at the files
+	lastChecked time.Time
+}
+
+type textureAsset struct {
+	texture *sdl.Texture
+	refs    int
+
+	// File the texture was loaded from and its mtime at the time. file is
+	// empty for textures loaded from FS, those can't change.
+	file    string
+	modTime time.Time
+}
+
+func NewAssets(r *sdl.Renderer) *Assets {
+	searchPaths := []string{"."}
+	if exe, err := os.Executable(); err == nil {
+		searchPaths = append(searchPaths, filepath.Dir(exe))
+	}
+
+	return &Assets{
+		SearchPaths: searchPaths,
+		renderer:    r,
+		textures:    make(map[string]*textureAsset),
+		paths:       make(map[*sdl.Texture]string),
+		freed:       make(map[*sdl.Texture]string),
+	}
+}
+
+// Texture returns the texture for path, loading it on first use.
+func (a *Assets) Texture(path string) (*sdl.Texture, error) {
+	if asset, ok := a.textures[path]; ok {
+		asset.refs += 1
+		return asset.texture, nil
+	}
+
+	texture, file, err := a.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &textureAsset{texture: texture, refs: 1, file: file}
+	if file != "" {
+		if info, err := os.Stat(file); err == nil {
+			asset.modTime = info.ModTime()
+		}
+	}
+	a.textures[path] = asset
+	a.paths[texture] = path
+	if debug {
+		delete(a.freed, texture)
+	}
+	return texture, nil
+}
+
+// load reads path from the first search path that has it, then from FS. It
+// also returns the file it was loaded from, empty when it came from FS.
+func (a *Assets) load(path string) (*sdl.Texture, string, error) {
+	file, name, err := a.resolve(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if file == "" {
+		texture, err := LoadTextureFS(a.FS, name, a.renderer)
+		return texture, "", err
+	}
+	texture, err := LoadTexture(file, a.renderer)
+	return texture, file, err
+}
+
+// ReadFile reads any asset, like sprite sheet data or maps, from the search
+// paths or FS.
+func (a *Assets) ReadFile(path string) ([]byte, error) {
+	file, name, err := a.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == "" {
+		return fs.ReadFile(a.FS, name)
+	}
+	return os.ReadFile(file)
+}
+
+// resolve finds path in the search paths and returns the file on disk, or
+// the name inside FS when it's only there.
+func (a *Assets) resolve(path string) (string, string, error) {
+	if filepath.IsAbs(path) {
+		return path, "", nil
+	}
+
+	for _, dir := range a.SearchPaths {
+		full := filepath.Join(dir, path)
+		if _, err := os.Stat(full); err == nil {
+			return full, "", nil
+		}
+	}
+
+	if a.FS != nil {
+		// fs.FS paths are always slash separated
+		name := filepath.ToSlash(path)
+		if _, err := fs.Stat(a.FS, name); err == nil {
+			return "", name, nil
+		}
+	}
+
+	return "", "", errors.New(fmt.Sprintf("assets: %s not found in %v or embedded files", path, a.SearchPaths))
+}
+
+// Release drops one reference to t and destroys it when nobody else uses
+// it.
+func (a *Assets) Release(t *sdl.Texture) {
+	path, ok := a.paths[t]
+	if !ok {
+		if debug {
+			if freedPath, wasFreed := a.freed[t]; wasFreed {
+				log.Printf("assets: double free of texture %s", freedPath)
+			} else {
+				log.Printf("assets: release of unknown texture %p", t)
+			}
+		}
+		return
+	}
+
+	asset := a.textures[path]
+	asset.refs -= 1
+	if asset.refs > 0 {
+		return
+	}
+	a.destroy(path)
+}
+
+// Refs is the number of references held on the texture for path.
+func (a *Assets) Refs(path string) int {
+	if asset, ok := a.textures[path]; ok {
+		return asset.refs
+	}
+	return 0
+}
+
+// DestroyAll destroys every texture, referenced or not.
+func (a *Assets) DestroyAll() {
+	for path, asset := range a.textures {
+		if debug {
+			log.Printf("assets: leaked texture %s (%d refs)", path, asset.refs)
+		}
+		a.destroy(path)
+	}
+}
+
+func (a *Assets) destroy(path string) {
+	asset := a.textures[path]
+	asset.texture.Destroy()
+
+	delete(a.textures, path)
+	delete(a.paths, asset.texture)
+	if debug {
+		a.freed[asset.texture] = path
+	}
+}
@@ -0,0 +1,44 @@
+//go:build debug
+
+package engine
+
+import (
+	"bytes"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssetsLeakReport(t *testing.T) {
+	w, dir := testAssets(t)
+	defer w.Cleanup()
+	a := w.Assets
+	writeImage(t, filepath.Join(dir, "a.png"), 4, 4, color.RGBA{255, 0, 0, 255}, time.Now())
+
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	texture, err := a.Texture("a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Texture("a.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	a.DestroyAll()
+	if !strings.Contains(out.String(), "leaked texture a.png (2 refs)") {
+		t.Errorf("No leak report for a.png, got %q", out.String())
+	}
+
+	out.Reset()
+	a.Release(texture)
+	if !strings.Contains(out.String(), "double free of texture a.png") {
+		t.Errorf("No double free report for a.png, got %q", out.String())
+	}
+}
@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testAssets is a headless window whose assets are only looked up in a temp
+// dir.
+func testAssets(t *testing.T) (*Window, string) {
+	w, err := NewHeadlessWindow("assets", 8, 8, 60)
+	if err != nil {
+		t.Skip("No SDL to load textures with.", err)
+	}
+	dir := t.TempDir()
+	w.Assets.SearchPaths = []string{dir}
+	return w, dir
+}
+
+// writeImage saves a w x h image of c to file and sets its mtime.
+func writeImage(t *testing.T, file string, w, h int, c color.RGBA, modTime time.Time) {
+	if err := WritePNG(file, solidImage(w, h, c)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAssetsRefCount(t *testing.T) {
+	w, dir := testAssets(t)
+	defer w.Cleanup()
+	a := w.Assets
+	writeImage(t, filepath.Join(dir, "a.png"), 4, 4, color.RGBA{255, 0, 0, 255}, time.Now())
+
+	first, err := a.Texture("a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := a.Texture("a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || a.Refs("a.png") != 2 {
+		t.Fatalf("Got textures %p and %p with %d refs, want the same one with 2", first, second, a.Refs("a.png"))
+	}
+
+	a.Release(first)
+	if a.Refs("a.png") != 1 || a.paths[first] != "a.png" {
+		t.Errorf("%d refs after one Release, want 1 and the texture still loaded", a.Refs("a.png"))
+	}
+
+	a.Release(second)
+	if a.Refs("a.png") != 0 || len(a.textures) != 0 || len(a.paths) != 0 {
+		t.Errorf("%d refs, %d textures after the last Release, want it destroyed", a.Refs("a.png"), len(a.textures))
+	}
+
+	// Releasing it again does nothing
+	a.Release(second)
+	if len(a.textures) != 0 {
+		t.Errorf("%d textures after a double Release", len(a.textures))
+	}
+
+	// Loaded again on the next use
+	third, err := a.Texture("a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Refs("a.png") != 1 || a.paths[third] != "a.png" {
+		t.Errorf("%d refs after loading again, want 1", a.Refs("a.png"))
+	}
+
+	a.DestroyAll()
+	if len(a.textures) != 0 || len(a.paths) != 0 {
+		t.Errorf("%d textures left after DestroyAll", len(a.textures))
+	}
+
+	if _, err := a.Texture("missing.png"); err == nil {
+		t.Error("Loaded a file that isn't there")
+	}
+}
+
+func TestAssetsResolve(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(first, "both.txt"):      "first",
+		filepath.Join(second, "both.txt"):     "second",
+		filepath.Join(second, "second.txt"):   "second",
+		filepath.Join(second, "sub", "a.txt"): "second",
+	}
+	for file, data := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := NewAssets(nil)
+	a.SearchPaths = []string{first, second}
+	a.FS = fstest.MapFS{
+		"both.txt":     {Data: []byte("fs")},
+		"second.txt":   {Data: []byte("fs")},
+		"embedded.txt": {Data: []byte("fs")},
+		"sub/b.txt":    {Data: []byte("fs")},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		// Search paths in order, then the FS
+		{"both.txt", "first"},
+		{"second.txt", "second"},
+		{"embedded.txt", "fs"},
+		{"sub/a.txt", "second"},
+		{"sub/b.txt", "fs"},
+		{filepath.Join(second, "second.txt"), "second"},
+	}
+	for _, test := range tests {
+		data, err := a.ReadFile(test.path)
+		if err != nil {
+			t.Errorf("%s: %s", test.path, err)
+			continue
+		}
+		if string(data) != test.want {
+			t.Errorf("%s: read from %s, want %s", test.path, data, test.want)
+		}
+	}
+
+	if _, err := a.ReadFile("missing.txt"); err == nil {
+		t.Error("Read a file that isn't anywhere")
+	}
+	a.FS = nil
+	if _, err := a.ReadFile("embedded.txt"); err == nil {
+		t.Error("Read an embedded file without an FS")
+	}
+}
+
+func TestLoadTextureFS(t *testing.T) {
+	w, _ := testAssets(t)
+	defer w.Cleanup()
+
+	fsys := fstest.MapFS{
+		"a.png":     {Data: pngBytes(t, solidImage(3, 5, color.RGBA{255, 0, 0, 255}))},
+		"empty.png": {Data: []byte{}},
+		"bad.png":   {Data: []byte("not a png")},
+	}
+	texture, err := LoadTextureFS(fsys, "a.png", w.Renderer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer texture.Destroy()
+	if tw, th, err := QueryTexture(texture); err != nil || tw != 3 || th != 5 {
+		t.Errorf("Texture is %dx%d (%v), want 3x5", tw, th, err)
+	}
+
+	for _, name := range []string{"empty.png", "bad.png", "missing.png"} {
+		if _, err := LoadTextureFS(fsys, name, w.Renderer()); err == nil {
+			t.Errorf("%s: loaded", name)
+		}
+	}
+}
+
+func pngBytes(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
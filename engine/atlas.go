@@ -0,0 +1,293 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Atlas packs many small images into a few big textures (pages) when the game
+// loads, so drawing them doesn't switch textures all the time. Add() images,
+// Build() once, then draw the regions with RenderRegion() or cut them into
+// sprite sheets:
+//
+//	atlas := NewAtlas(1024)
+//	atlas.AddFile(w.Assets, "dude.png")
+//	atlas.AddFile(w.Assets, "yoshi_trans_animation.png")
+//	if err := atlas.Build(w.Renderer()); err != nil { ... }
+//	defer atlas.Destroy()
+//
+//	dude, _ := atlas.Region("dude.png")
+//	RenderOriginalRegion(dude, w.Renderer(), x, y)
+type Atlas struct {
+	// Width and height of a page
+	Size int
+
+	// Transparent pixels around every image, so scaled drawing doesn't bleed
+	// in pixels from the neighbours
+	Padding int
+
+	Pages   []*sdl.Texture
+	regions map[string]*TextureRegion
+
+	// Images waiting for Build(), then the page pixels kept for Dump()
+	images     []atlasImage
+	pageImages []*image.RGBA
+	placements map[string]atlasPlacement
+	built      bool
+}
+
+type atlasImage struct {
+	name string
+	img  *image.RGBA
+}
+
+type atlasPlacement struct {
+	page int
+	rect image.Rectangle
+}
+
+func NewAtlas(size int) *Atlas {
+	return &Atlas{
+		Size:       size,
+		Padding:    1,
+		regions:    make(map[string]*TextureRegion),
+		placements: make(map[string]atlasPlacement),
+	}
+}
+
+// Add queues an image for the next Build(). name is what Region() looks it
+// up by.
+func (a *Atlas) Add(name string, img image.Image) {
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	a.images = append(a.images, atlasImage{name: name, img: rgba})
+}
+
+// AddFile loads an image through assets (any format SDL_image reads) and
+// queues it under its path.
+func (a *Atlas) AddFile(assets *Assets, path string) error {
+	data, err := assets.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return errors.New(fmt.Sprintf("Failed to load image: %s is empty", path))
+	}
+
+	rw, free, err := rwFromBytes(data)
+	if err != nil {
+		return err
+	}
+	defer free()
+
+	surface := img.LoadRW(rw, true)
+	if surface == nil {
+		return sdlError("IMG_Load_RW")
+	}
+	defer surface.Free()
+
+	rgba, err := surfaceToRGBA(surface)
+	if err != nil {
+		return err
+	}
+	a.images = append(a.images, atlasImage{name: path, img: rgba})
+	return nil
+}
+
+// Build packs every queued image into as many pages as it takes and uploads
+// them as textures for r. An atlas is only built once, images added after
+// that go in another atlas.
+func (a *Atlas) Build(r *sdl.Renderer) error {
+	if err := a.pack(); err != nil {
+		return err
+	}
+
+	for _, pageImage := range a.pageImages {
+		texture, err := textureFromRGBA(r, pageImage)
+		if err != nil {
+			a.Destroy()
+			return err
+		}
+		a.Pages = append(a.Pages, texture)
+	}
+
+	for name, placed := range a.placements {
+		a.regions[name] = &TextureRegion{
+			Texture: a.Pages[placed.page],
+			Rect: sdl.Rect{
+				X: int32(placed.rect.Min.X),
+				Y: int32(placed.rect.Min.Y),
+				W: int32(placed.rect.Dx()),
+				H: int32(placed.rect.Dy()),
+			},
+		}
+	}
+	return nil
+}
+
+// pack places the queued images on the pages, without SDL.
+func (a *Atlas) pack() error {
+	if a.built {
+		return errors.New("Atlas is already built")
+	}
+
+	// Check them all first, so nothing is packed when one is too big
+	for _, queued := range a.images {
+		w := queued.img.Bounds().Dx() + 2*a.Padding
+		h := queued.img.Bounds().Dy() + 2*a.Padding
+		if w > a.Size || h > a.Size {
+			return errors.New(fmt.Sprintf("Image %s (%dx%d) doesn't fit a %dx%d atlas page",
+				queued.name, w, h, a.Size, a.Size))
+		}
+	}
+	a.built = true
+
+	// Tallest first packs tighter
+	sort.SliceStable(a.images, func(i, j int) bool {
+		return a.images[i].img.Bounds().Dy() > a.images[j].img.Bounds().Dy()
+	})
+
+	var packers []*skyline
+	for _, queued := range a.images {
+		w := queued.img.Bounds().Dx() + 2*a.Padding
+		h := queued.img.Bounds().Dy() + 2*a.Padding
+
+		page, x, y := -1, 0, 0
+		for i, packer := range packers {
+			var ok bool
+			if x, y, ok = packer.insert(w, h); ok {
+				page = i
+				break
+			}
+		}
+		if page < 0 {
+			packers = append(packers, newSkyline(a.Size, a.Size))
+			a.pageImages = append(a.pageImages, image.NewRGBA(image.Rect(0, 0, a.Size, a.Size)))
+			page = len(packers) - 1
+			x, y, _ = packers[page].insert(w, h)
+		}
+
+		rect := queued.img.Bounds().Add(image.Pt(x+a.Padding, y+a.Padding))
+		draw.Draw(a.pageImages[page], rect, queued.img, image.Point{}, draw.Src)
+		a.placements[queued.name] = atlasPlacement{page: page, rect: rect}
+	}
+	a.images = nil
+	return nil
+}
+
+// Region is where the image added as name ended up.
+func (a *Atlas) Region(name string) (*TextureRegion, bool) {
+	reg, ok := a.regions[name]
+	return reg, ok
+}
+
+// Dump writes every page to dir as atlas_N.png, with an atlas_N.json index in
+// the TexturePacker hash format, to see what the packer did. The JSON also
+// loads back with Assets.SpriteSheet().
+func (a *Atlas) Dump(dir string) error {
+	type rectJSON struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	}
+	type frameJSON struct {
+		Frame   rectJSON `json:"frame"`
+		Rotated bool     `json:"rotated"`
+	}
+	type indexJSON struct {
+		Frames map[string]frameJSON `json:"frames"`
+		Meta   struct {
+			Image string `json:"image"`
+			Size  struct {
+				W int `json:"w"`
+				H int `json:"h"`
+			} `json:"size"`
+		} `json:"meta"`
+	}
+
+	for page, pageImage := range a.pageImages {
+		base := fmt.Sprintf("atlas_%d", page)
+		if err := WritePNG(filepath.Join(dir, base+".png"), pageImage); err != nil {
+			return err
+		}
+
+		var index indexJSON
+		index.Frames = make(map[string]frameJSON)
+		index.Meta.Image = base + ".png"
+		index.Meta.Size.W, index.Meta.Size.H = a.Size, a.Size
+		for name, placed := range a.placements {
+			if placed.page != page {
+				continue
+			}
+			r := placed.rect
+			index.Frames[name] = frameJSON{Frame: rectJSON{r.Min.X, r.Min.Y, r.Dx(), r.Dy()}}
+		}
+
+		data, err := json.MarshalIndent(index, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, base+".json"), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Destroy frees the page textures.
+func (a *Atlas) Destroy() {
+	for _, page := range a.Pages {
+		page.Destroy()
+	}
+	a.Pages = nil
+}
+
+// surfaceToRGBA copies a surface of any pixel format into an image.RGBA.
+func surfaceToRGBA(s *sdl.Surface) (*image.RGBA, error) {
+	converted := sdl.ConvertSurfaceFormat(s, sdl.PIXELFORMAT_ABGR8888, 0)
+	if converted == nil {
+		return nil, sdlError("SDL_ConvertSurfaceFormat")
+	}
+	defer converted.Free()
+
+	rgba := image.NewRGBA(image.Rect(0, 0, int(converted.W), int(converted.H)))
+	pixels := converted.Pixels()
+	for y := 0; y < rgba.Rect.Dy(); y++ {
+		row := pixels[y*int(converted.Pitch):]
+		copy(rgba.Pix[y*rgba.Stride:(y+1)*rgba.Stride], row)
+	}
+	return rgba, nil
+}
+
+// textureFromRGBA uploads img as a texture with alpha blending.
+func textureFromRGBA(r *sdl.Renderer, img *image.RGBA) (*sdl.Texture, error) {
+	// Copied into a surface SDL allocates, SDL can't keep a pointer to
+	// img.Pix (cgo's pointer passing rules)
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	surface := sdl.CreateRGBSurface(0, w, h, 32, rmask, gmask, bmask, amask)
+	if surface == nil {
+		return nil, sdlError("SDL_CreateRGBSurface")
+	}
+	defer surface.Free()
+
+	pixels := surface.Pixels()
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+4*w]
+		copy(pixels[y*int(surface.Pitch):], row)
+	}
+
+	texture := r.CreateTextureFromSurface(surface)
+	if texture == nil {
+		return nil, sdlError("SDL_CreateTextureFromSurface")
+	}
+	return texture, nil
+}
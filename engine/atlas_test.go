@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func solidImage(w, h int, c color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{c}, image.Point{}, draw.Src)
+	return img
+}
+
+func TestAtlasPack(t *testing.T) {
+	a := NewAtlas(64)
+	a.Padding = 2
+	colors := map[string]color.RGBA{}
+	sizes := map[string][2]int{
+		"big":    {40, 40},
+		"wide":   {50, 10},
+		"small1": {8, 8},
+		"small2": {8, 8},
+		// Doesn't fit next to big, goes on a new page
+		"tall": {30, 56},
+	}
+	i := 0
+	for name, size := range sizes {
+		c := color.RGBA{uint8(40 * i), 100, uint8(255 - 40*i), 255}
+		colors[name] = c
+		a.Add(name, solidImage(size[0], size[1], c))
+		i++
+	}
+	if err := a.pack(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a.pageImages) != 2 {
+		t.Errorf("%d pages, want 2", len(a.pageImages))
+	}
+	for name, size := range sizes {
+		placed, ok := a.placements[name]
+		if !ok {
+			t.Fatalf("%s not placed", name)
+		}
+		r := placed.rect
+		if r.Dx() != size[0] || r.Dy() != size[1] {
+			t.Errorf("%s is %dx%d, want %dx%d", name, r.Dx(), r.Dy(), size[0], size[1])
+		}
+		// Padding all around, inside the page
+		if !r.Inset(-a.Padding).In(image.Rect(0, 0, a.Size, a.Size)) {
+			t.Errorf("%s at %v, padding off the page", name, r)
+		}
+		for other, op := range a.placements {
+			if other != name && op.page == placed.page && r.Inset(-a.Padding).Overlaps(op.rect) {
+				t.Errorf("%s at %v overlaps %s's padding at %v", other, op.rect, name, r)
+			}
+		}
+
+		page := a.pageImages[placed.page]
+		if page.RGBAAt(r.Min.X, r.Min.Y) != colors[name] || page.RGBAAt(r.Max.X-1, r.Max.Y-1) != colors[name] {
+			t.Errorf("%s's pixels aren't at %v", name, r)
+		}
+		if page.RGBAAt(r.Min.X-1, r.Min.Y-1).A != 0 {
+			t.Errorf("%s's padding isn't transparent", name)
+		}
+	}
+
+	if err := a.pack(); err == nil {
+		t.Error("packed twice")
+	}
+}
+
+// An image too big for a page is an error before anything is packed.
+func TestAtlasTooBig(t *testing.T) {
+	a := NewAtlas(32)
+	a.Add("ok", solidImage(8, 8, color.RGBA{A: 255}))
+	a.Add("huge", solidImage(31, 8, color.RGBA{A: 255}))
+	if err := a.pack(); err == nil {
+		t.Fatal("packed a 33 px wide image in a 32 px page")
+	}
+	if len(a.pageImages) != 0 || len(a.placements) != 0 {
+		t.Errorf("%d pages, %d placements after the error", len(a.pageImages), len(a.placements))
+	}
+}
+
+func TestAtlasBuild(t *testing.T) {
+	w, err := NewHeadlessWindow("atlas", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to make textures with.", err)
+	}
+	defer w.Cleanup()
+
+	a := NewAtlas(64)
+	a.Add("a", solidImage(10, 20, color.RGBA{255, 0, 0, 255}))
+	a.Add("b", solidImage(30, 5, color.RGBA{0, 255, 0, 255}))
+	if err := a.Build(w.Renderer()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Destroy()
+
+	for _, name := range []string{"a", "b"} {
+		reg, ok := a.Region(name)
+		placed := a.placements[name].rect
+		if !ok || reg.Texture != a.Pages[0] || int(reg.Rect.X) != placed.Min.X || int(reg.Rect.Y) != placed.Min.Y ||
+			int(reg.Rect.W) != placed.Dx() || int(reg.Rect.H) != placed.Dy() {
+			t.Errorf("%s: region %+v, placed at %v", name, reg, placed)
+		}
+	}
+
+	a.Add("c", solidImage(4, 4, color.RGBA{A: 255}))
+	if err := a.Build(w.Renderer()); err == nil {
+		t.Error("built twice")
+	}
+	if len(a.Pages) != 1 {
+		t.Errorf("%d pages after the second Build, want 1", len(a.Pages))
+	}
+}
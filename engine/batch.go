@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"sort"
+	"unsafe"
+)
+
+// DrawCommand is one texture copy waiting in a RenderQueue.
+type DrawCommand struct {
+	Texture  *sdl.Texture
+	Src, Dst sdl.Rect
+	Flip     sdl.RendererFlip
+
+	// Lower layers are drawn first, e.g. 0 background, 1 entities, 2 UI
+	Layer int
+
+	// Orders draws of the same texture within a layer, lower first
+	Z float64
+}
+
+// RenderStats counts what the last RenderQueue.Flush() did.
+type RenderStats struct {
+	DrawCalls       int
+	TextureSwitches int
+}
+
+// RenderQueue collects the frame's draws instead of copying right away, then
+// sorts them by layer, then texture, and submits them all at once in Flush().
+// Grouping by texture cuts texture switches, which is where atlases pay off.
+//
+// Within a layer draws with different textures can end up in any order. Put
+// things that must be drawn over each other on different layers, Z only
+// orders draws that share a texture.
+type RenderQueue struct {
+	commands []DrawCommand
+	stats    RenderStats
+}
+
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{}
+}
+
+// Submit queues a draw for the next Flush().
+func (q *RenderQueue) Submit(cmd DrawCommand) {
+	q.commands = append(q.commands, cmd)
+}
+
+// Len is the number of queued draws.
+func (q *RenderQueue) Len() int {
+	return len(q.commands)
+}
+
+// Flush sorts the queued draws, copies them to r and empties the queue.
+func (q *RenderQueue) Flush(r *sdl.Renderer) error {
+	q.sort()
+
+	q.stats = RenderStats{}
+	var current *sdl.Texture
+	for i := range q.commands {
+		cmd := &q.commands[i]
+		if cmd.Texture != current {
+			q.stats.TextureSwitches += 1
+			current = cmd.Texture
+		}
+
+		var err error
+		if cmd.Flip != sdl.FLIP_NONE {
+			err = CopyEx(r, cmd.Texture, &cmd.Src, &cmd.Dst, 0, nil, cmd.Flip)
+		} else {
+			err = Copy(r, cmd.Texture, &cmd.Src, &cmd.Dst)
+		}
+		if err != nil {
+			q.commands = q.commands[:0]
+			return err
+		}
+		q.stats.DrawCalls += 1
+	}
+
+	q.commands = q.commands[:0]
+	return nil
+}
+
+// sort puts the queued draws in drawing order: by layer, then texture, then
+// Z. Draws that tie keep the order they were submitted in.
+func (q *RenderQueue) sort() {
+	sort.SliceStable(q.commands, func(i, j int) bool {
+		a, b := &q.commands[i], &q.commands[j]
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		if a.Texture != b.Texture {
+			return uintptr(unsafe.Pointer(a.Texture)) < uintptr(unsafe.Pointer(b.Texture))
+		}
+		return a.Z < b.Z
+	})
+}
+
+// Stats is what the last Flush() drew.
+func (q *RenderQueue) Stats() RenderStats {
+	return q.stats
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"image"
+	"image/color"
+	"testing"
+	"unsafe"
+)
+
+// Sorting only compares texture pointers, these stand in for real textures.
+// They're never drawn. Lower index, lower address.
+var fakeTextureMem [3]byte
+
+func fakeTexture(i int) *sdl.Texture {
+	return (*sdl.Texture)(unsafe.Pointer(&fakeTextureMem[i]))
+}
+
+// drawCmd is a command told apart from the others by its id, kept in Dst.X.
+func drawCmd(id int32, layer, texture int, z float64) DrawCommand {
+	return DrawCommand{Texture: fakeTexture(texture), Dst: sdl.Rect{X: id}, Layer: layer, Z: z}
+}
+
+func TestRenderQueueSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []DrawCommand
+		order    []int32
+	}{
+		{
+			"layer first",
+			[]DrawCommand{drawCmd(1, 2, 0, 0), drawCmd(2, 0, 2, 5), drawCmd(3, 1, 1, -5)},
+			[]int32{2, 3, 1},
+		},
+		{
+			"then texture",
+			[]DrawCommand{drawCmd(1, 0, 2, 0), drawCmd(2, 0, 0, 9), drawCmd(3, 0, 1, 0), drawCmd(4, 0, 0, 1)},
+			[]int32{4, 2, 3, 1},
+		},
+		{
+			"then z",
+			[]DrawCommand{drawCmd(1, 0, 0, 3), drawCmd(2, 0, 0, -1), drawCmd(3, 0, 0, 2.5)},
+			[]int32{2, 3, 1},
+		},
+		{
+			"ties keep submit order",
+			[]DrawCommand{drawCmd(1, 0, 0, 1), drawCmd(2, 0, 0, 0), drawCmd(3, 0, 0, 1), drawCmd(4, 0, 0, 0), drawCmd(5, 0, 0, 1)},
+			[]int32{2, 4, 1, 3, 5},
+		},
+		{
+			"everything",
+			[]DrawCommand{
+				drawCmd(1, 1, 0, 0), drawCmd(2, 0, 1, 1), drawCmd(3, 0, 0, 0), drawCmd(4, 1, 0, 0),
+				drawCmd(5, 0, 1, 0), drawCmd(6, 0, 0, 0), drawCmd(7, 1, 2, -1), drawCmd(8, 0, 1, 1),
+			},
+			[]int32{3, 6, 5, 2, 8, 1, 4, 7},
+		},
+	}
+
+	for _, test := range tests {
+		q := NewRenderQueue()
+		for _, cmd := range test.commands {
+			q.Submit(cmd)
+		}
+		q.sort()
+
+		var order []int32
+		for _, cmd := range q.commands {
+			order = append(order, cmd.Dst.X)
+		}
+		if len(order) != len(test.order) {
+			t.Errorf("%s: got %v, expected %v", test.name, order, test.order)
+			continue
+		}
+		for i := range order {
+			if order[i] != test.order[i] {
+				t.Errorf("%s: got %v, expected %v", test.name, order, test.order)
+				break
+			}
+		}
+	}
+}
+
+func TestRenderQueueFlush(t *testing.T) {
+	w, err := NewHeadlessWindow("batch", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to render with.", err)
+	}
+	defer w.Cleanup()
+
+	r := w.Renderer()
+	var textures []*sdl.Texture
+	for i := 0; i < 2; i++ {
+		texture, err := textureFromRGBA(r, solidImage(8, 8, color.RGBA{255, 0, 0, 255}).(*image.RGBA))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer texture.Destroy()
+		textures = append(textures, texture)
+	}
+	a, b := textures[0], textures[1]
+	src := sdl.Rect{W: 8, H: 8}
+
+	q := NewRenderQueue()
+	q.Submit(DrawCommand{Texture: a, Src: src, Dst: src})
+	q.Submit(DrawCommand{Texture: b, Src: src, Dst: src, Flip: sdl.FLIP_HORIZONTAL})
+	q.Submit(DrawCommand{Texture: a, Src: src, Dst: src, Z: 1})
+	q.Submit(DrawCommand{Texture: b, Src: src, Dst: src})
+	q.Submit(DrawCommand{Texture: a, Src: src, Dst: src, Layer: 1})
+	if q.Len() != 5 {
+		t.Fatalf("Len() is %d, expected 5", q.Len())
+	}
+
+	if err := q.Flush(r); err != nil {
+		t.Fatal(err)
+	}
+	// Layer 0 draws a twice then b twice (or the other way), then layer 1
+	// goes back to a
+	stats := q.Stats()
+	if stats.DrawCalls != 5 || stats.TextureSwitches != 3 {
+		t.Errorf("Stats are %+v, expected 5 draw calls and 3 texture switches", stats)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() is %d after Flush, expected 0", q.Len())
+	}
+
+	if err := q.Flush(r); err != nil {
+		t.Fatal(err)
+	}
+	if stats := q.Stats(); stats != (RenderStats{}) {
+		t.Errorf("Stats of an empty flush are %+v, expected zeros", stats)
+	}
+}
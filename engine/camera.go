@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+)
+
+// Target is something a Camera can follow, e.g. a Sprite.
+type Target interface {
+	// Center of the target in world coordinates
+	Center() (float64, float64)
+}
+
+// Camera is a view into a world bigger than the window. X, Y is the world
+// position shown at the top left corner of the viewport, scaled by Zoom.
+//
+// Like the draw color, the camera is renderer state: after SetCamera(r, c)
+// every draw through Copy(), and so every render helper, Sprite and
+// RenderQueue, takes dst rects in world coordinates. SetCamera(r, nil) goes
+// back to screen coordinates, for UI.
+type Camera struct {
+	X, Y float64
+	Zoom float64
+
+	// Size of the screen area the camera draws to
+	ViewportW, ViewportH int
+
+	// Entity to keep in view. Update() moves the camera.
+	Target Target
+
+	// The target moves freely inside this box (world units, centered on the
+	// view) before the camera follows.
+	DeadZoneW, DeadZoneH float64
+
+	// How fast the camera catches up with the target, in fractions of the
+	// distance left per second. 0 snaps to the target.
+	FollowSpeed float64
+
+	// The camera never shows anything outside these world bounds, see
+	// SetBounds()
+	bounds    sdl.Rect
+	hasBounds bool
+}
+
+func NewCamera(viewportW, viewportH int) *Camera {
+	return &Camera{
+		Zoom:      1,
+		ViewportW: viewportW,
+		ViewportH: viewportH,
+	}
+}
+
+// SetBounds keeps the view inside the world rect x, y, w, h.
+func (c *Camera) SetBounds(x, y, w, h int) {
+	c.bounds = sdl.Rect{X: int32(x), Y: int32(y), W: int32(w), H: int32(h)}
+	c.hasBounds = true
+}
+
+// ViewSize is how much of the world, in world units, the camera shows.
+func (c *Camera) ViewSize() (float64, float64) {
+	return float64(c.ViewportW) / c.Zoom, float64(c.ViewportH) / c.Zoom
+}
+
+// Visible is the part of the world in view, rounded out to whole pixels.
+func (c *Camera) Visible() sdl.Rect {
+	w, h := c.ViewSize()
+	x, y := math.Floor(c.X), math.Floor(c.Y)
+	return sdl.Rect{
+		X: int32(x),
+		Y: int32(y),
+		W: int32(math.Ceil(c.X+w) - x),
+		H: int32(math.Ceil(c.Y+h) - y),
+	}
+}
+
+// Update follows the target, if any, and applies the bounds.
+func (c *Camera) Update(dt float64) {
+	if c.Target != nil {
+		viewW, viewH := c.ViewSize()
+		centerX, centerY := c.X+viewW/2, c.Y+viewH/2
+		targetX, targetY := c.Target.Center()
+
+		wantX := deadZone(centerX, targetX, c.DeadZoneW/2)
+		wantY := deadZone(centerY, targetY, c.DeadZoneH/2)
+
+		if c.FollowSpeed > 0 {
+			t := math.Min(1, c.FollowSpeed*dt)
+			wantX = centerX + (wantX-centerX)*t
+			wantY = centerY + (wantY-centerY)*t
+		}
+		c.X, c.Y = wantX-viewW/2, wantY-viewH/2
+	}
+
+	c.clamp()
+}
+
+// deadZone is where center has to move so target is at most half away.
+func deadZone(center, target, half float64) float64 {
+	if target > center+half {
+		return target - half
+	}
+	if target < center-half {
+		return target + half
+	}
+	return center
+}
+
+func (c *Camera) clamp() {
+	if !c.hasBounds {
+		return
+	}
+	viewW, viewH := c.ViewSize()
+	c.X = clampView(c.X, viewW, float64(c.bounds.X), float64(c.bounds.W))
+	c.Y = clampView(c.Y, viewH, float64(c.bounds.Y), float64(c.bounds.H))
+}
+
+// clampView keeps pos .. pos+view inside min .. min+size, or centers the view
+// when the world is smaller than it.
+func clampView(pos, view, min, size float64) float64 {
+	if view >= size {
+		return min + (size-view)/2
+	}
+	return math.Max(min, math.Min(pos, min+size-view))
+}
+
+// WorldToScreen converts a world position to the screen.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x - c.X) * c.Zoom, (y - c.Y) * c.Zoom
+}
+
+// ScreenToWorld converts a screen position, like the mouse, to the world.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	return x/c.Zoom + c.X, y/c.Zoom + c.Y
+}
+
+// WorldToScreenRect converts a world rect to the screen.
+func (c *Camera) WorldToScreenRect(rect sdl.Rect) sdl.Rect {
+	x1, y1 := c.WorldToScreen(float64(rect.X), float64(rect.Y))
+	x2, y2 := c.WorldToScreen(float64(rect.X+rect.W), float64(rect.Y+rect.H))
+
+	// Round both edges so neighbouring tiles don't leave gaps when zoomed
+	x1, y1 = math.Floor(x1), math.Floor(y1)
+	return sdl.Rect{
+		X: int32(x1),
+		Y: int32(y1),
+		W: int32(math.Floor(x2) - x1),
+		H: int32(math.Floor(y2) - y1),
+	}
+}
+
+// Cameras in use, by renderer. See SetCamera().
+var cameras = make(map[*sdl.Renderer]*Camera)
+
+// SetCamera makes every draw to r go through c. nil draws in screen
+// coordinates again.
+func SetCamera(r *sdl.Renderer, c *Camera) {
+	if c == nil {
+		delete(cameras, r)
+		return
+	}
+	cameras[r] = c
+}
+
+// CameraFor is the camera set on r, nil when there is none.
+func CameraFor(r *sdl.Renderer) *Camera {
+	return cameras[r]
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"testing"
+)
+
+type point struct {
+	x, y float64
+}
+
+func (p *point) Center() (float64, float64) {
+	return p.x, p.y
+}
+
+func nearF(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCameraConversions(t *testing.T) {
+	tests := []struct {
+		name             string
+		camX, camY       float64
+		zoom             float64
+		worldX, worldY   float64
+		screenX, screenY float64
+	}{
+		{"origin", 0, 0, 1, 10, 20, 10, 20},
+		{"scrolled", 100, 50, 1, 110, 70, 10, 20},
+		{"zoomed in", 100, 50, 2, 110, 70, 20, 40},
+		{"zoomed out", 100, 50, 0.5, 110, 70, 5, 10},
+		{"left of the view", 100, 50, 2, 90, 50, -20, 0},
+	}
+	for _, test := range tests {
+		c := NewCamera(320, 240)
+		c.X, c.Y, c.Zoom = test.camX, test.camY, test.zoom
+		x, y := c.WorldToScreen(test.worldX, test.worldY)
+		if !nearF(x, test.screenX) || !nearF(y, test.screenY) {
+			t.Errorf("%s: world %v,%v on screen at %v,%v, want %v,%v",
+				test.name, test.worldX, test.worldY, x, y, test.screenX, test.screenY)
+		}
+		x, y = c.ScreenToWorld(test.screenX, test.screenY)
+		if !nearF(x, test.worldX) || !nearF(y, test.worldY) {
+			t.Errorf("%s: screen %v,%v in the world at %v,%v, want %v,%v",
+				test.name, test.screenX, test.screenY, x, y, test.worldX, test.worldY)
+		}
+	}
+
+	c := NewCamera(320, 240)
+	c.X, c.Y, c.Zoom = 10.5, 0, 2
+	// Edges rounded down, so tiles side by side meet
+	if got, want := c.WorldToScreenRect(sdl.Rect{X: 20, Y: 5, W: 16, H: 16}), (sdl.Rect{X: 19, Y: 10, W: 32, H: 32}); got != want {
+		t.Errorf("WorldToScreenRect = %v, want %v", got, want)
+	}
+}
+
+func TestCameraFollow(t *testing.T) {
+	tests := []struct {
+		name         string
+		deadW, deadH float64
+		speed        float64
+		target       point
+		wantX, wantY float64
+	}{
+		// The view is 100x100 at 0,0, its center 50,50
+		{"snaps to the target", 0, 0, 0, point{200, 50}, 150, 0},
+		{"inside the dead zone", 40, 40, 0, point{65, 35}, 0, 0},
+		{"pushing the dead zone", 40, 40, 0, point{90, 10}, 20, -20},
+		{"catching up slowly", 0, 0, 5, point{150, 50}, 50, 0},
+	}
+	for _, test := range tests {
+		c := NewCamera(100, 100)
+		c.DeadZoneW, c.DeadZoneH = test.deadW, test.deadH
+		c.FollowSpeed = test.speed
+		target := test.target
+		c.Target = &target
+		// 5 a second for 0.1 s is half the way
+		c.Update(0.1)
+		if !nearF(c.X, test.wantX) || !nearF(c.Y, test.wantY) {
+			t.Errorf("%s: camera at %v,%v, want %v,%v", test.name, c.X, c.Y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestCameraBounds(t *testing.T) {
+	tests := []struct {
+		name         string
+		zoom         float64
+		x, y         float64
+		bounds       sdl.Rect
+		wantX, wantY float64
+	}{
+		{"inside", 1, 50, 50, sdl.Rect{W: 400, H: 300}, 50, 50},
+		{"past the top left", 1, -20, -5, sdl.Rect{W: 400, H: 300}, 0, 0},
+		{"past the bottom right", 1, 350, 290, sdl.Rect{W: 400, H: 300}, 300, 200},
+		{"bounds not at 0", 1, 0, 0, sdl.Rect{X: 50, Y: 60, W: 400, H: 300}, 50, 60},
+		{"zoomed in sees less", 2, 400, 400, sdl.Rect{W: 400, H: 300}, 350, 250},
+		// Smaller than the view: centered, whatever the camera wanted
+		{"level smaller than the view", 1, 30, 30, sdl.Rect{W: 60, H: 300}, -20, 30},
+		{"zoomed out past the level", 0.5, 30, 30, sdl.Rect{W: 100, H: 100}, -50, -50},
+	}
+	for _, test := range tests {
+		c := NewCamera(100, 100)
+		c.Zoom = test.zoom
+		c.X, c.Y = test.x, test.y
+		b := test.bounds
+		c.SetBounds(int(b.X), int(b.Y), int(b.W), int(b.H))
+		c.Update(0.1)
+		if !nearF(c.X, test.wantX) || !nearF(c.Y, test.wantY) {
+			t.Errorf("%s: camera at %v,%v, want %v,%v", test.name, c.X, c.Y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestCameraVisible(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y float64
+		zoom float64
+		want sdl.Rect
+	}{
+		{"whole pixels", 10, 20, 1, sdl.Rect{X: 10, Y: 20, W: 100, H: 50}},
+		{"rounded out", 10.5, 20.25, 1, sdl.Rect{X: 10, Y: 20, W: 101, H: 51}},
+		{"zoomed in", 10, 20, 2, sdl.Rect{X: 10, Y: 20, W: 50, H: 25}},
+		{"zoomed out", -10, 0, 0.5, sdl.Rect{X: -10, Y: 0, W: 200, H: 100}},
+	}
+	for _, test := range tests {
+		c := NewCamera(100, 50)
+		c.X, c.Y, c.Zoom = test.x, test.y, test.zoom
+		if got := c.Visible(); got != test.want {
+			t.Errorf("%s: Visible() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCleanupForgetsCamera(t *testing.T) {
+	w, err := NewHeadlessWindow("camera", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to make a renderer with.", err)
+	}
+	r := w.Renderer()
+	SetCamera(r, NewCamera(64, 64))
+	w.Cleanup()
+	if CameraFor(r) != nil {
+		t.Error("camera still set on the destroyed renderer")
+	}
+}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"image"
+	"image/png"
+	"os"
+	"unsafe"
+)
+
+// ReadPixels copies what has been rendered so far into an image. Call it
+// before Present(), accelerated renderers don't keep the back buffer around
+// after presenting.
+func (w *Window) ReadPixels() (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, w.Width, w.Height))
+
+	// ABGR8888 is R, G, B, A byte order on little endian machines, the same
+	// layout as image.RGBA.Pix
+	ret := w.renderer.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, unsafe.Pointer(&img.Pix[0]), img.Stride)
+	if ret < 0 {
+		return nil, sdlError("SDL_RenderReadPixels")
+	}
+	return img, nil
+}
+
+// SavePNG writes the current frame (see ReadPixels) to a PNG file.
+func (w *Window) SavePNG(path string) error {
+	img, err := w.ReadPixels()
+	if err != nil {
+		return err
+	}
+	return WritePNG(path, img)
+}
+
+// WritePNG encodes img to a PNG file at path.
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
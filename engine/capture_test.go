@@ -0,0 +1,46 @@
+package engine_test
+
+import (
+	"github.com/paydro/gamedev/engine"
+	"github.com/paydro/gamedev/engine/enginetest"
+	"testing"
+)
+
+// Yoshi's frame 3 drawn at 100, 100 on the demos' gray background.
+func TestYoshiFrameGolden(t *testing.T) {
+	w, err := engine.NewHeadlessWindow("golden", 320, 240, 60)
+	if err != nil {
+		t.Skip("No SDL to render with.", err)
+	}
+	defer w.Cleanup()
+	w.Assets.SearchPaths = append(w.Assets.SearchPaths, "..")
+
+	sheet, _, err := w.Assets.SpriteSheet("yoshi_trans_animation.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Assets.Release(sheet.Texture)
+
+	yoshi := engine.NewSprite(sheet, 16)
+	yoshi.Time = engine.NewManualTime()
+	yoshi.AddClip(engine.NewClip("frame3", []int{3}, 16, engine.PlayLoop))
+	yoshi.Play("frame3")
+	yoshi.DestX, yoshi.DestY = 100, 100
+
+	r := w.Renderer()
+	if err := engine.SetDrawColor(r, 205, 205, 205, 255); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Clear(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := yoshi.Draw(r, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	frame, err := w.ReadPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enginetest.AssertGolden(t, frame, "testdata/yoshi_frame3.png", 2)
+}
@@ -0,0 +1,58 @@
+package engine
+
+// Clock caps the game loop to FPS frames a second. It behaves similarily to
+// pygame's clock().
+type Clock struct {
+	LastTick uint32
+	FPS      float32
+
+	// Where time comes from. nil means SDLTime.
+	Time TimeSource
+
+	started bool
+
+	// Fraction of a ms left over from the previous frames. Frames are
+	// whole ms, 16 or 17 at 60 FPS, this keeps them at 16.67 on average.
+	carry float64
+}
+
+func NewClock(fps int) *Clock {
+	return &Clock{FPS: float32(fps), Time: SDLTime{}}
+}
+
+// Tick sleeps whatever is left of the current frame and returns how many ms
+// passed since the previous Tick, sleep included. Call it once at the top of
+// the game loop. The first call returns 0.
+func (c *Clock) Tick() int {
+	t := timeSource(c.Time)
+
+	currentTick := t.Ticks()
+
+	if !c.started {
+		c.started = true
+		c.LastTick = currentTick
+		return 0
+	}
+
+	processedIn := currentTick - c.LastTick
+	if frameMs := c.frameMs(); frameMs > processedIn {
+		t.Delay(frameMs - processedIn)
+	}
+
+	currentTick = t.Ticks()
+	elapsed := currentTick - c.LastTick
+	c.LastTick = currentTick
+	return int(elapsed)
+}
+
+// frameMs is how long this frame should last in whole ms.
+func (c *Clock) frameMs() uint32 {
+	if c.FPS <= 0 {
+		// Uncapped
+		return 0
+	}
+	c.carry += 1000.0 / float64(c.FPS)
+	ms := uint32(c.carry)
+	c.carry -= float64(ms)
+	return ms
+}
@@ -0,0 +1,149 @@
+package collision
+
+import (
+	"math"
+)
+
+// Manifold is how two overlapping shapes, a and b, collide.
+type Manifold struct {
+	// Unit vector from a towards b. Moving a by -Normal*Depth (or b by
+	// Normal*Depth, or each by half) separates them.
+	Normal Vec
+	Depth  float64
+
+	// Where they touch
+	Contact Vec
+}
+
+// Flip is the manifold the other way round, for b against a.
+func (m Manifold) Flip() Manifold {
+	m.Normal = m.Normal.Neg()
+	return m
+}
+
+// Overlaps tells if a and b overlap. Shapes that only touch don't.
+func Overlaps(a, b Shape) bool {
+	_, ok := Collide(a, b)
+	return ok
+}
+
+// Collide tests any two shapes and returns their manifold when they
+// overlap. Points are handled as circles of radius 0, two points never
+// collide.
+func Collide(a, b Shape) (Manifold, bool) {
+	switch a := a.(type) {
+	case Rect:
+		switch b := b.(type) {
+		case Rect:
+			return CollideRects(a, b)
+		case Circle:
+			return CollideRectCircle(a, b)
+		case Vec:
+			return CollideRectCircle(a, Circle{b, 0})
+		}
+	case Circle:
+		switch b := b.(type) {
+		case Rect:
+			m, ok := CollideRectCircle(b, a)
+			return m.Flip(), ok
+		case Circle:
+			return CollideCircles(a, b)
+		case Vec:
+			return CollideCircles(a, Circle{b, 0})
+		}
+	case Vec:
+		switch b := b.(type) {
+		case Rect:
+			m, ok := CollideRectCircle(b, Circle{a, 0})
+			return m.Flip(), ok
+		case Circle:
+			return CollideCircles(Circle{a, 0}, b)
+		}
+	}
+	return Manifold{}, false
+}
+
+// CollideRects separates two rects along the axis they overlap the least.
+func CollideRects(a, b Rect) (Manifold, bool) {
+	left, right := math.Max(a.X, b.X), math.Min(a.X+a.W, b.X+b.W)
+	top, bottom := math.Max(a.Y, b.Y), math.Min(a.Y+a.H, b.Y+b.H)
+	ox, oy := right-left, bottom-top
+	if ox <= 0 || oy <= 0 {
+		return Manifold{}, false
+	}
+
+	m := Manifold{Contact: Vec{(left + right) / 2, (top + bottom) / 2}}
+	ac, bc := a.Center(), b.Center()
+	if ox < oy {
+		m.Depth = ox
+		m.Normal = Vec{1, 0}
+		if bc.X < ac.X {
+			m.Normal.X = -1
+		}
+	} else {
+		m.Depth = oy
+		m.Normal = Vec{0, 1}
+		if bc.Y < ac.Y {
+			m.Normal.Y = -1
+		}
+	}
+	return m, true
+}
+
+// CollideCircles pushes circles apart along the line between their centers.
+// Circles with the same center are pushed apart vertically, a up.
+func CollideCircles(a, b Circle) (Manifold, bool) {
+	d := b.Center.Sub(a.Center)
+	dist := d.Len()
+	radii := a.Radius + b.Radius
+	if dist >= radii {
+		return Manifold{}, false
+	}
+
+	normal := Vec{0, 1}
+	if dist > 0 {
+		normal = d.Scale(1 / dist)
+	}
+	depth := radii - dist
+	return Manifold{
+		Normal:  normal,
+		Depth:   depth,
+		Contact: a.Center.Add(normal.Scale(a.Radius - depth/2)),
+	}, true
+}
+
+// CollideRectCircle tests a rect against a circle. A circle whose center is
+// inside the rect is pushed out through the nearest edge.
+func CollideRectCircle(r Rect, c Circle) (Manifold, bool) {
+	closest := r.Closest(c.Center)
+	d := c.Center.Sub(closest)
+	if dist := d.Len(); dist > 0 {
+		if dist >= c.Radius {
+			return Manifold{}, false
+		}
+		return Manifold{
+			Normal:  d.Scale(1 / dist),
+			Depth:   c.Radius - dist,
+			Contact: closest,
+		}, true
+	}
+
+	// Center inside: find the nearest edge
+	p := c.Center
+	m := Manifold{Normal: Vec{-1, 0}, Depth: p.X - r.X, Contact: Vec{r.X, p.Y}}
+	if right := r.X + r.W - p.X; right < m.Depth {
+		m = Manifold{Normal: Vec{1, 0}, Depth: right, Contact: Vec{r.X + r.W, p.Y}}
+	}
+	if top := p.Y - r.Y; top < m.Depth {
+		m = Manifold{Normal: Vec{0, -1}, Depth: top, Contact: Vec{p.X, r.Y}}
+	}
+	if bottom := r.Y + r.H - p.Y; bottom < m.Depth {
+		m = Manifold{Normal: Vec{0, 1}, Depth: bottom, Contact: Vec{p.X, r.Y + r.H}}
+	}
+	m.Depth += c.Radius
+	if m.Depth <= 0 {
+		// A point on the edge
+		return Manifold{}, false
+	}
+	return m, true
+}
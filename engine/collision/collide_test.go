@@ -0,0 +1,87 @@
+package collision
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCollide(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   Shape
+		hit    bool
+		normal Vec
+		depth  float64
+	}{
+		{"rects apart", R(0, 0, 10, 10), R(20, 0, 10, 10), false, Vec{}, 0},
+		{"rects touching", R(0, 0, 10, 10), R(10, 0, 10, 10), false, Vec{}, 0},
+		{"rect overlapping on the right", R(0, 0, 10, 10), R(8, 2, 10, 4), true, V(1, 0), 2},
+		{"rect overlapping on the left", R(0, 0, 10, 10), R(-8, 2, 10, 4), true, V(-1, 0), 2},
+		{"rect overlapping below", R(0, 0, 10, 10), R(2, 7, 4, 10), true, V(0, 1), 3},
+		{"rect overlapping above", R(0, 0, 10, 10), R(2, -3, 4, 4), true, V(0, -1), 1},
+		{"circles apart", C(0, 0, 5), C(10, 0, 5), false, Vec{}, 0},
+		{"circles overlapping", C(0, 0, 5), C(6, 0, 2), true, V(1, 0), 1},
+		{"circles diagonal", C(0, 0, 5), C(3, 4, 1), true, V(0.6, 0.8), 1},
+		{"circles on the same center", C(0, 0, 5), C(0, 0, 2), true, V(0, 1), 7},
+		{"rect and circle apart", R(0, 0, 10, 10), C(14, 5, 3), false, Vec{}, 0},
+		{"rect and circle on the side", R(0, 0, 10, 10), C(12, 5, 3), true, V(1, 0), 1},
+		{"circle and rect, flipped", C(12, 5, 3), R(0, 0, 10, 10), true, V(-1, 0), 1},
+		{"circle center inside the rect", R(0, 0, 10, 10), C(2, 5, 1), true, V(-1, 0), 3},
+		{"point inside a rect", V(2, 5), R(0, 0, 10, 10), true, V(1, 0), 2},
+		{"point on a rect's edge", V(0, 5), R(0, 0, 10, 10), false, Vec{}, 0},
+		{"two points", V(1, 1), V(1, 1), false, Vec{}, 0},
+	}
+
+	for _, test := range tests {
+		m, ok := Collide(test.a, test.b)
+		if ok != test.hit {
+			t.Errorf("%s: hit %v, want %v", test.name, ok, test.hit)
+			continue
+		}
+		if Overlaps(test.a, test.b) != test.hit {
+			t.Errorf("%s: Overlaps disagrees with Collide", test.name)
+		}
+		if !ok {
+			continue
+		}
+		if !nearVec(m.Normal, test.normal) || !near(m.Depth, test.depth) {
+			t.Errorf("%s: normal %v depth %v, want %v depth %v", test.name, m.Normal, m.Depth, test.normal, test.depth)
+		}
+	}
+}
+
+// Moving a back along the normal by the depth separates the shapes.
+func TestCollideSeparates(t *testing.T) {
+	pairs := [][2]Shape{
+		{R(0, 0, 10, 10), R(8, 2, 10, 4)},
+		{R(0, 0, 10, 10), R(2, -3, 4, 4)},
+		{C(0, 0, 5), C(3, 4, 1)},
+		{R(0, 0, 10, 10), C(12, 5, 3)},
+	}
+	for _, p := range pairs {
+		m, ok := Collide(p[0], p[1])
+		if !ok {
+			t.Fatalf("%v and %v don't collide", p[0], p[1])
+		}
+		// A hair more, shapes right against each other only touch
+		push := m.Normal.Scale(-(m.Depth + 1e-9))
+		var moved Shape
+		switch a := p[0].(type) {
+		case Rect:
+			moved = a.Moved(push)
+		case Circle:
+			moved = Circle{a.Center.Add(push), a.Radius}
+		}
+		if Overlaps(moved, p[1]) {
+			t.Errorf("%v still overlaps %v after the push", moved, p[1])
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func nearVec(a, b Vec) bool {
+	return near(a.X, b.X) && near(a.Y, b.Y)
+}
@@ -0,0 +1,82 @@
+package collision
+
+import (
+	"math"
+)
+
+// Shape is a Rect, a Circle or a Vec used as a point.
+type Shape interface {
+	// Smallest Rect around the shape
+	Bounds() Rect
+}
+
+// Rect is an axis aligned box, X, Y being its top left corner.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+func R(x, y, w, h float64) Rect {
+	return Rect{x, y, w, h}
+}
+
+func (r Rect) Bounds() Rect {
+	return r
+}
+
+func (r Rect) Min() Vec {
+	return Vec{r.X, r.Y}
+}
+
+func (r Rect) Max() Vec {
+	return Vec{r.X + r.W, r.Y + r.H}
+}
+
+func (r Rect) Center() Vec {
+	return Vec{r.X + r.W/2, r.Y + r.H/2}
+}
+
+// Moved is r moved by d.
+func (r Rect) Moved(d Vec) Rect {
+	return Rect{r.X + d.X, r.Y + d.Y, r.W, r.H}
+}
+
+// Contains is true for points inside r or on its edges.
+func (r Rect) Contains(p Vec) bool {
+	return p.X >= r.X && p.X <= r.X+r.W && p.Y >= r.Y && p.Y <= r.Y+r.H
+}
+
+// Closest is the point of r nearest to p, p itself when it's inside.
+func (r Rect) Closest(p Vec) Vec {
+	return Vec{
+		math.Max(r.X, math.Min(p.X, r.X+r.W)),
+		math.Max(r.Y, math.Min(p.Y, r.Y+r.H)),
+	}
+}
+
+type Circle struct {
+	Center Vec
+	Radius float64
+}
+
+func C(x, y, radius float64) Circle {
+	return Circle{Vec{x, y}, radius}
+}
+
+func (c Circle) Bounds() Rect {
+	return Rect{c.Center.X - c.Radius, c.Center.Y - c.Radius, 2 * c.Radius, 2 * c.Radius}
+}
+
+func (c Circle) Contains(p Vec) bool {
+	return p.Sub(c.Center).Len() <= c.Radius
+}
+
+// A point is a zero sized rect
+func (v Vec) Bounds() Rect {
+	return Rect{v.X, v.Y, 0, 0}
+}
+
+// Overlaps is the quick test for two rects, for when the manifold isn't
+// needed. Rects that only touch don't overlap.
+func (r Rect) Overlaps(o Rect) bool {
+	return r.X < o.X+o.W && o.X < r.X+r.W && r.Y < o.Y+o.H && o.Y < r.Y+r.H
+}
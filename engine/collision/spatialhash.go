@@ -0,0 +1,184 @@
+package collision
+
+import (
+	"math"
+)
+
+// SpatialHash is a broad phase: it buckets rects in a uniform grid so only
+// things that share a cell get tested against each other, instead of every
+// pair. Pick a cell size about twice the size of a typical entity.
+//
+// Entities are known by an id of the caller's choosing, e.g. an index in
+// its entity slice. Space is unbounded, cells only exist while something is
+// in them.
+type SpatialHash struct {
+	CellSize float64
+
+	cells map[cellKey][]int
+	items map[int]*hashItem
+
+	// Bumped on every query, marks items already returned
+	stamp int
+}
+
+type cellKey struct {
+	x, y int
+}
+
+type hashItem struct {
+	bounds Rect
+
+	// Cells covered, inclusive
+	x0, y0, x1, y1 int
+
+	stamp int
+}
+
+func NewSpatialHash(cellSize float64) *SpatialHash {
+	return &SpatialHash{
+		CellSize: cellSize,
+		cells:    map[cellKey][]int{},
+		items:    map[int]*hashItem{},
+	}
+}
+
+func (h *SpatialHash) Len() int {
+	return len(h.items)
+}
+
+// Insert adds id with its bounds. Inserting an id already there moves it.
+func (h *SpatialHash) Insert(id int, bounds Rect) {
+	if _, ok := h.items[id]; ok {
+		h.Move(id, bounds)
+		return
+	}
+
+	item := &hashItem{bounds: bounds}
+	item.x0, item.y0, item.x1, item.y1 = h.cellRange(bounds)
+	h.items[id] = item
+	h.add(id, item)
+}
+
+// Move updates the bounds of id. Cheap when it stays in the same cells,
+// which is most frames for most things.
+func (h *SpatialHash) Move(id int, bounds Rect) {
+	item, ok := h.items[id]
+	if !ok {
+		h.Insert(id, bounds)
+		return
+	}
+
+	item.bounds = bounds
+	x0, y0, x1, y1 := h.cellRange(bounds)
+	if x0 == item.x0 && y0 == item.y0 && x1 == item.x1 && y1 == item.y1 {
+		return
+	}
+	h.remove(id, item)
+	item.x0, item.y0, item.x1, item.y1 = x0, y0, x1, y1
+	h.add(id, item)
+}
+
+func (h *SpatialHash) Remove(id int) {
+	item, ok := h.items[id]
+	if !ok {
+		return
+	}
+	h.remove(id, item)
+	delete(h.items, id)
+}
+
+// Bounds of id as last inserted or moved.
+func (h *SpatialHash) Bounds(id int) (Rect, bool) {
+	item, ok := h.items[id]
+	if !ok {
+		return Rect{}, false
+	}
+	return item.bounds, true
+}
+
+// Query appends to found the ids whose bounds overlap r, each once, and
+// returns it. Pass found[:0] from the last call to not allocate every frame.
+func (h *SpatialHash) Query(r Rect, found []int) []int {
+	h.stamp++
+	x0, y0, x1, y1 := h.cellRange(r)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			for _, id := range h.cells[cellKey{x, y}] {
+				item := h.items[id]
+				if item.stamp == h.stamp {
+					continue
+				}
+				item.stamp = h.stamp
+				if item.bounds.Overlaps(r) {
+					found = append(found, id)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// Pairs calls fn once for every pair of ids whose bounds overlap. These are
+// candidates, run the narrow phase (Collide) on them.
+func (h *SpatialHash) Pairs(fn func(a, b int)) {
+	for key, ids := range h.cells {
+		for i, a := range ids {
+			ia := h.items[a]
+			for _, b := range ids[i+1:] {
+				ib := h.items[b]
+				// Things sharing several cells meet in each of them. Only
+				// report the pair in the top left cell they share.
+				if key.x != maxInt(ia.x0, ib.x0) || key.y != maxInt(ia.y0, ib.y0) {
+					continue
+				}
+				if ia.bounds.Overlaps(ib.bounds) {
+					fn(a, b)
+				}
+			}
+		}
+	}
+}
+
+func (h *SpatialHash) cellRange(r Rect) (int, int, int, int) {
+	return int(math.Floor(r.X / h.CellSize)),
+		int(math.Floor(r.Y / h.CellSize)),
+		int(math.Floor((r.X + r.W) / h.CellSize)),
+		int(math.Floor((r.Y + r.H) / h.CellSize))
+}
+
+func (h *SpatialHash) add(id int, item *hashItem) {
+	for y := item.y0; y <= item.y1; y++ {
+		for x := item.x0; x <= item.x1; x++ {
+			key := cellKey{x, y}
+			h.cells[key] = append(h.cells[key], id)
+		}
+	}
+}
+
+func (h *SpatialHash) remove(id int, item *hashItem) {
+	for y := item.y0; y <= item.y1; y++ {
+		for x := item.x0; x <= item.x1; x++ {
+			key := cellKey{x, y}
+			ids := h.cells[key]
+			for i, other := range ids {
+				if other == id {
+					ids[i] = ids[len(ids)-1]
+					ids = ids[:len(ids)-1]
+					break
+				}
+			}
+			if len(ids) == 0 {
+				delete(h.cells, key)
+			} else {
+				h.cells[key] = ids
+			}
+		}
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,213 @@
+package collision
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Bullets for the benchmarks. A frame moves every bullet, re-buckets it and
+// lists the overlapping pairs, which has to fit in a 60 FPS frame (16.7 ms)
+// with room to spare for everything else:
+//
+//	go test -bench . ./engine/collision
+const (
+	benchFPS      = 60
+	entitySize    = 16.0
+	cellSize      = 2 * entitySize
+	entitySpeed   = 120.0 // pixels per second
+	areaPerEntity = 64.0 * 64.0
+)
+
+// testWorld is bullets bouncing around a world that grows with the number
+// of bullets, so the density stays the same.
+type testWorld struct {
+	size   float64
+	bounds []Rect
+	vel    []Vec
+	hash   *SpatialHash
+}
+
+func newTestWorld(n int) *testWorld {
+	rng := rand.New(rand.NewSource(1))
+	w := &testWorld{
+		size: math.Sqrt(float64(n) * areaPerEntity),
+		hash: NewSpatialHash(cellSize),
+	}
+	for i := 0; i < n; i++ {
+		angle := rng.Float64() * 2 * math.Pi
+		r := R(rng.Float64()*(w.size-entitySize), rng.Float64()*(w.size-entitySize), entitySize, entitySize)
+		w.bounds = append(w.bounds, r)
+		w.vel = append(w.vel, V(math.Cos(angle)*entitySpeed, math.Sin(angle)*entitySpeed))
+		w.hash.Insert(i, r)
+	}
+	return w
+}
+
+func (w *testWorld) step(dt float64) {
+	for i := range w.bounds {
+		r := w.bounds[i].Moved(w.vel[i].Scale(dt))
+		if r.X < 0 || r.X+r.W > w.size {
+			w.vel[i].X = -w.vel[i].X
+		}
+		if r.Y < 0 || r.Y+r.H > w.size {
+			w.vel[i].Y = -w.vel[i].Y
+		}
+		w.bounds[i] = r
+		w.hash.Move(i, r)
+	}
+}
+
+// The pairs with the broad phase, sorted
+func (w *testWorld) pairs() [][2]int {
+	var pairs [][2]int
+	w.hash.Pairs(func(a, b int) {
+		if a > b {
+			a, b = b, a
+		}
+		pairs = append(pairs, [2]int{a, b})
+	})
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0] || pairs[i][0] == pairs[j][0] && pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+// The pairs checking everything against everything, sorted
+func (w *testWorld) allPairs() [][2]int {
+	var pairs [][2]int
+	for a := range w.bounds {
+		for b := a + 1; b < len(w.bounds); b++ {
+			if w.bounds[a].Overlaps(w.bounds[b]) {
+				pairs = append(pairs, [2]int{a, b})
+			}
+		}
+	}
+	return pairs
+}
+
+func TestSpatialHashQuery(t *testing.T) {
+	h := NewSpatialHash(10)
+	h.Insert(1, R(0, 0, 5, 5))
+	// Spans four cells, still found once
+	h.Insert(2, R(8, 8, 5, 5))
+	h.Insert(3, R(-30, -30, 5, 5))
+
+	tests := []struct {
+		name  string
+		r     Rect
+		found []int
+	}{
+		{"top left", R(0, 0, 4, 4), []int{1}},
+		{"across cells", R(0, 0, 20, 20), []int{1, 2}},
+		{"negative cells", R(-28, -28, 1, 1), []int{3}},
+		{"same cell, no overlap", R(6, 6, 1, 1), nil},
+		{"nothing there", R(100, 100, 10, 10), nil},
+	}
+	for _, test := range tests {
+		found := h.Query(test.r, nil)
+		sort.Ints(found)
+		if !equalInts(found, test.found) {
+			t.Errorf("%s: found %v, want %v", test.name, found, test.found)
+		}
+	}
+
+	h.Move(2, R(-28, -28, 5, 5))
+	if found := h.Query(R(10, 10, 1, 1), nil); len(found) != 0 {
+		t.Errorf("found %v where 2 was", found)
+	}
+	found := h.Query(R(-28, -28, 1, 1), nil)
+	sort.Ints(found)
+	if !equalInts(found, []int{2, 3}) {
+		t.Errorf("found %v after the move, want [2 3]", found)
+	}
+
+	h.Remove(3)
+	if found := h.Query(R(-30, -30, 1, 1), nil); len(found) != 0 || h.Len() != 2 {
+		t.Errorf("found %v and %d items after the remove", found, h.Len())
+	}
+}
+
+// Every overlapping pair comes out of Pairs, once.
+func TestSpatialHashPairs(t *testing.T) {
+	w := newTestWorld(500)
+	for frame := 0; frame < 30; frame++ {
+		w.step(1.0 / benchFPS)
+		got, want := w.pairs(), w.allPairs()
+		if len(got) != len(want) {
+			t.Fatalf("frame %d: %d pairs, want %d", frame, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("frame %d: pair %v, want %v", frame, got[i], want[i])
+			}
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkSpatialHash(b *testing.B, n int) {
+	b.Run("insert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newTestWorld(n)
+		}
+	})
+	b.Run("move", func(b *testing.B) {
+		w := newTestWorld(n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			w.step(1.0 / benchFPS)
+		}
+	})
+	b.Run("query", func(b *testing.B) {
+		w := newTestWorld(n)
+		var found []int
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, r := range w.bounds {
+				found = w.hash.Query(r, found[:0])
+			}
+		}
+	})
+	b.Run("frame", func(b *testing.B) {
+		w := newTestWorld(n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			w.step(1.0 / benchFPS)
+			w.hash.Pairs(func(a, c int) {
+				Overlaps(w.bounds[a], w.bounds[c])
+			})
+		}
+	})
+}
+
+// What the spatial hash replaces: every pair, every frame
+func benchmarkAllPairs(b *testing.B, n int) {
+	w := newTestWorld(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.step(1.0 / benchFPS)
+		for a := range w.bounds {
+			for c := a + 1; c < len(w.bounds); c++ {
+				Overlaps(w.bounds[a], w.bounds[c])
+			}
+		}
+	}
+}
+
+func BenchmarkSpatialHash1k(b *testing.B)  { benchmarkSpatialHash(b, 1000) }
+func BenchmarkSpatialHash10k(b *testing.B) { benchmarkSpatialHash(b, 10000) }
+func BenchmarkAllPairs1k(b *testing.B)     { benchmarkAllPairs(b, 1000) }
+func BenchmarkAllPairs10k(b *testing.B)    { benchmarkAllPairs(b, 10000) }
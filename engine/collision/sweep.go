@@ -0,0 +1,118 @@
+package collision
+
+import (
+	"math"
+)
+
+// Hit is where a ray, or a moving shape, first touches something.
+type Hit struct {
+	// Fraction of the ray or of the move done before touching, 0 to 1
+	Time float64
+
+	// Where the ray hits. For sweeps, where the center of the moving shape
+	// is when it touches.
+	Point Vec
+
+	// Normal of the surface hit. Zero when the ray starts inside.
+	Normal Vec
+}
+
+// RaycastRect casts a ray from origin along dir and returns where it enters
+// r. The ray is as long as dir, anything past it is a miss. A ray starting
+// inside r hits at Time 0. Grazing an edge or a corner doesn't count.
+func RaycastRect(origin, dir Vec, r Rect) (Hit, bool) {
+	near, far := math.Inf(-1), math.Inf(1)
+	var normal Vec
+
+	axes := [2]struct{ o, d, lo, hi float64 }{
+		{origin.X, dir.X, r.X, r.X + r.W},
+		{origin.Y, dir.Y, r.Y, r.Y + r.H},
+	}
+	for i, a := range axes {
+		if a.d == 0 {
+			// Parallel to this axis, it has to be between the edges
+			if a.o <= a.lo || a.o >= a.hi {
+				return Hit{}, false
+			}
+			continue
+		}
+
+		t1, t2 := (a.lo-a.o)/a.d, (a.hi-a.o)/a.d
+		side := -1.0
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			side = 1
+		}
+		if t1 > near {
+			near = t1
+			normal = Vec{}
+			if i == 0 {
+				normal.X = side
+			} else {
+				normal.Y = side
+			}
+		}
+		far = math.Min(far, t2)
+	}
+
+	if near >= far || far <= 0 || near > 1 {
+		return Hit{}, false
+	}
+	if near < 0 {
+		return Hit{Point: origin}, true
+	}
+	return Hit{Time: near, Point: origin.Add(dir.Scale(near)), Normal: normal}, true
+}
+
+// RaycastCircle casts a ray from origin along dir and returns where it
+// enters c. Same rules as RaycastRect.
+func RaycastCircle(origin, dir Vec, c Circle) (Hit, bool) {
+	m := origin.Sub(c.Center)
+	k := m.Dot(m) - c.Radius*c.Radius
+	if k < 0 {
+		return Hit{Point: origin}, true
+	}
+
+	// Solve |origin + t*dir - center| = radius for t
+	a := dir.Dot(dir)
+	b := m.Dot(dir)
+	disc := b*b - a*k
+	if a == 0 || disc <= 0 {
+		return Hit{}, false
+	}
+	t := (-b - math.Sqrt(disc)) / a
+	if t < 0 || t > 1 {
+		return Hit{}, false
+	}
+
+	p := origin.Add(dir.Scale(t))
+	return Hit{Time: t, Point: p, Normal: p.Sub(c.Center).Normalize()}, true
+}
+
+// SweepRects moves a by delta and returns when it first touches b, so fast
+// movers can't pass through thin walls between frames. Move a by
+// delta*Time to put it against b, then deal with the rest of the move, e.g.
+// slide along Normal. Rects that overlap to begin with hit at Time 0 with the
+// normal to push a out of b.
+func SweepRects(a Rect, delta Vec, b Rect) (Hit, bool) {
+	// Growing b by a's size turns a moving box into a ray from its center
+	grown := Rect{b.X - a.W/2, b.Y - a.H/2, b.W + a.W, b.H + a.H}
+	hit, ok := RaycastRect(a.Center(), delta, grown)
+	if ok && hit.Time == 0 && hit.Normal == (Vec{}) {
+		if m, overlap := CollideRects(a, b); overlap {
+			hit.Normal = m.Normal.Neg()
+		}
+	}
+	return hit, ok
+}
+
+// SweepCircles moves a by delta and returns when it first touches b.
+func SweepCircles(a Circle, delta Vec, b Circle) (Hit, bool) {
+	hit, ok := RaycastCircle(a.Center, delta, Circle{b.Center, a.Radius + b.Radius})
+	if ok && hit.Time == 0 && hit.Normal == (Vec{}) {
+		if m, overlap := CollideCircles(a, b); overlap {
+			hit.Normal = m.Normal.Neg()
+		}
+	}
+	return hit, ok
+}
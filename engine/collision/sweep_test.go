@@ -0,0 +1,108 @@
+package collision
+
+import (
+	"testing"
+)
+
+func TestSweepRects(t *testing.T) {
+	wall := R(20, 0, 10, 40)
+	tests := []struct {
+		name   string
+		a      Rect
+		delta  Vec
+		hit    bool
+		time   float64
+		normal Vec
+	}{
+		{"hits the wall halfway", R(0, 10, 10, 10), V(20, 0), true, 0.5, V(-1, 0)},
+		{"hits from the other side", R(40, 10, 10, 10), V(-20, 0), true, 0.5, V(1, 0)},
+		{"stops short", R(0, 10, 10, 10), V(5, 0), false, 0, Vec{}},
+		{"moves away", R(0, 10, 10, 10), V(-20, 0), false, 0, Vec{}},
+		{"passes over", R(0, -30, 10, 10), V(40, 0), false, 0, Vec{}},
+		{"fast enough to tunnel", R(0, 10, 10, 10), V(1000, 0), true, 0.01, V(-1, 0)},
+		{"slides along the top", R(15, -10, 10, 10), V(10, 0), false, 0, Vec{}},
+		{"lands on top", R(22, -20, 4, 10), V(0, 20), true, 0.5, V(0, -1)},
+		{"already overlapping", R(15, 10, 10, 10), V(10, 0), true, 0, V(-1, 0)},
+		{"already overlapping, not moving", R(15, 10, 10, 10), V(0, 0), true, 0, V(-1, 0)},
+		{"not moving, apart", R(0, 10, 10, 10), V(0, 0), false, 0, Vec{}},
+	}
+
+	for _, test := range tests {
+		hit, ok := SweepRects(test.a, test.delta, wall)
+		if ok != test.hit {
+			t.Errorf("%s: hit %v, want %v", test.name, ok, test.hit)
+			continue
+		}
+		if ok && (!near(hit.Time, test.time) || !nearVec(hit.Normal, test.normal)) {
+			t.Errorf("%s: time %v normal %v, want %v normal %v", test.name, hit.Time, hit.Normal, test.time, test.normal)
+		}
+	}
+}
+
+func TestSweepRectsEndsAgainst(t *testing.T) {
+	a, wall, delta := R(0, 10, 10, 10), R(20, 0, 10, 40), V(30, 5)
+	hit, ok := SweepRects(a, delta, wall)
+	if !ok {
+		t.Fatal("no hit")
+	}
+	moved := a.Moved(delta.Scale(hit.Time))
+	if !near(moved.X+moved.W, wall.X) || moved.Overlaps(wall) {
+		t.Errorf("moved to %v, want right against %v", moved, wall)
+	}
+	if !nearVec(hit.Point, moved.Center()) {
+		t.Errorf("hit point %v, want the moved center %v", hit.Point, moved.Center())
+	}
+}
+
+func TestSweepCircles(t *testing.T) {
+	tests := []struct {
+		name   string
+		a      Circle
+		delta  Vec
+		hit    bool
+		time   float64
+		normal Vec
+	}{
+		{"head on", C(0, 0, 1), V(10, 0), true, 0.4, V(-1, 0)},
+		{"miss", C(0, 5, 1), V(10, 0), false, 0, Vec{}},
+		{"already overlapping", C(5, 0, 1), V(10, 0), true, 0, V(-1, 0)},
+		{"not moving", C(0, 0, 1), V(0, 0), false, 0, Vec{}},
+	}
+	for _, test := range tests {
+		hit, ok := SweepCircles(test.a, test.delta, C(6, 0, 1))
+		if ok != test.hit {
+			t.Errorf("%s: hit %v, want %v", test.name, ok, test.hit)
+			continue
+		}
+		if ok && (!near(hit.Time, test.time) || !nearVec(hit.Normal, test.normal)) {
+			t.Errorf("%s: time %v normal %v, want %v normal %v", test.name, hit.Time, hit.Normal, test.time, test.normal)
+		}
+	}
+}
+
+func TestRaycastRect(t *testing.T) {
+	box := R(0, 0, 10, 10)
+	tests := []struct {
+		name        string
+		origin, dir Vec
+		hit         bool
+		time        float64
+		normal      Vec
+	}{
+		{"from the left", V(-10, 5), V(20, 0), true, 0.5, V(-1, 0)},
+		{"from below", V(5, 20), V(0, -20), true, 0.5, V(0, 1)},
+		{"too short", V(-10, 5), V(5, 0), false, 0, Vec{}},
+		{"grazing an edge", V(-10, 0), V(20, 0), false, 0, Vec{}},
+		{"starting inside", V(5, 5), V(0, -20), true, 0, Vec{}},
+	}
+	for _, test := range tests {
+		hit, ok := RaycastRect(test.origin, test.dir, box)
+		if ok != test.hit {
+			t.Errorf("%s: hit %v, want %v", test.name, ok, test.hit)
+			continue
+		}
+		if ok && (!near(hit.Time, test.time) || !nearVec(hit.Normal, test.normal)) {
+			t.Errorf("%s: time %v normal %v, want %v normal %v", test.name, hit.Time, hit.Normal, test.time, test.normal)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Package collision is 2D collision math on float vectors: rectangles,
+// circles and points, overlap tests, contact manifolds, swept rectangles and
+// ray casts. It doesn't know about SDL or the engine, so it can be used (and
+// tested) on its own.
+//
+// Y points down like the screen: a normal of 0,-1 is a floor.
+//
+//	m, ok := collision.Collide(player, wall)
+//	if ok {
+//		// push the player out of the wall
+//		player.X -= m.Normal.X * m.Depth
+//		player.Y -= m.Normal.Y * m.Depth
+//	}
+package collision
+
+import (
+	"math"
+)
+
+// Vec is a 2D vector, or a point.
+type Vec struct {
+	X, Y float64
+}
+
+func V(x, y float64) Vec {
+	return Vec{x, y}
+}
+
+func (v Vec) Add(o Vec) Vec {
+	return Vec{v.X + o.X, v.Y + o.Y}
+}
+
+func (v Vec) Sub(o Vec) Vec {
+	return Vec{v.X - o.X, v.Y - o.Y}
+}
+
+func (v Vec) Scale(f float64) Vec {
+	return Vec{v.X * f, v.Y * f}
+}
+
+func (v Vec) Neg() Vec {
+	return Vec{-v.X, -v.Y}
+}
+
+func (v Vec) Dot(o Vec) float64 {
+	return v.X*o.X + v.Y*o.Y
+}
+
+func (v Vec) Len() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize returns v with a length of 1, or the zero vector for the zero
+// vector.
+func (v Vec) Normalize() Vec {
+	l := v.Len()
+	if l == 0 {
+		return Vec{}
+	}
+	return Vec{v.X / l, v.Y / l}
+}
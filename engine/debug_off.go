@@ -0,0 +1,7 @@
+//go:build !debug
+
+package engine
+
+// debug turns on the extra bookkeeping and logging of debug builds. Build
+// with `-tags debug`.
+const debug = false
@@ -0,0 +1,25 @@
+package engine
+
+type Direction int
+
+const (
+	UP Direction = iota
+	RIGHT
+	DOWN
+	LEFT
+)
+
+func (d Direction) String() string {
+	var s string
+	switch d {
+	case UP:
+		s = "UP"
+	case RIGHT:
+		s = "RIGHT"
+	case DOWN:
+		s = "DOWN"
+	case LEFT:
+		s = "LEFT"
+	}
+	return s
+}
@@ -0,0 +1,20 @@
+// Package engine is everything the gameXXX programs kept copy-pasting: the
+// Window, the frame Clock, texture helpers and animated sprites.
+//
+// A new demo only needs to create a Window, load its textures and write a
+// game loop. See game009 for an example.
+package engine
+
+import (
+	"runtime"
+)
+
+func init() {
+	// SDL2 render commands are supposed to run on the main thread. This keeps
+	// main to run on the same OS thread. Importing engine is enough, programs
+	// don't need their own init() anymore.
+	// See:
+	// * https://groups.google.com/forum/#!topic/golang-nuts/2_L7sPzC_6E
+	// * https://groups.google.com/forum/#!msg/golang-nuts/IiWZ2hUuLDA/SNKYYZBelsYJ
+	runtime.LockOSThread()
+}
@@ -0,0 +1,115 @@
+// Package enginetest has helpers for testing rendered frames against golden
+// PNG files checked into the repo.
+//
+// Render a frame in a headless window and compare it before Present():
+//
+//	w, _ := engine.NewHeadlessWindow("test", 320, 240, 60)
+//	defer w.Cleanup()
+//	... draw ...
+//	frame, err := w.ReadPixels()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	enginetest.AssertGolden(t, frame, "testdata/yoshi_frame3.png", 2)
+//
+// Run the tests with UPDATE_GOLDEN=1 to (re)write the golden files from the
+// current output.
+package enginetest
+
+import (
+	"github.com/paydro/gamedev/engine"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Diff compares two images pixel by pixel. A pixel differs when any of its
+// channels (0-255) is more than tolerance apart. It returns how many pixels
+// differ and an image with those pixels in red over a faded copy of want.
+// Images of different sizes differ everywhere.
+func Diff(got, want image.Image, tolerance uint8) (int, *image.RGBA) {
+	bounds := want.Bounds().Union(got.Bounds())
+	diff := image.NewRGBA(bounds)
+	sameSize := got.Bounds() == want.Bounds()
+
+	count := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(x, y)).(color.NRGBA)
+			wt := color.NRGBAModel.Convert(want.At(x, y)).(color.NRGBA)
+
+			if !sameSize || !channelsClose(g, wt, tolerance) {
+				count += 1
+				diff.Set(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+
+			// Faded grayscale of the expected pixel so the red stands out
+			gray := uint8((uint16(wt.R) + uint16(wt.G) + uint16(wt.B)) / 3)
+			gray = 192 + gray/4
+			diff.Set(x, y, color.RGBA{gray, gray, gray, 255})
+		}
+	}
+	return count, diff
+}
+
+func channelsClose(a, b color.NRGBA, tolerance uint8) bool {
+	return absDiff(a.R, b.R) <= tolerance &&
+		absDiff(a.G, b.G) <= tolerance &&
+		absDiff(a.B, b.B) <= tolerance &&
+		absDiff(a.A, b.A) <= tolerance
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// AssertGolden fails the test when got differs from the golden PNG by more
+// than tolerance per channel. On failure the actual frame and a diff image
+// are written next to the golden file as <name>.actual.png and
+// <name>.diff.png.
+func AssertGolden(t testing.TB, got image.Image, golden string, tolerance uint8) {
+	t.Helper()
+
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := engine.WritePNG(golden, got); err != nil {
+			t.Fatalf("Failed to update golden file %s: %s", golden, err)
+		}
+		return
+	}
+
+	want, err := readPNG(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file %s: %s", golden, err)
+	}
+
+	count, diff := Diff(got, want, tolerance)
+	if count == 0 {
+		return
+	}
+
+	base := strings.TrimSuffix(golden, ".png")
+	if err := engine.WritePNG(base+".actual.png", got); err != nil {
+		t.Errorf("Failed to write actual image: %s", err)
+	}
+	if err := engine.WritePNG(base+".diff.png", diff); err != nil {
+		t.Errorf("Failed to write diff image: %s", err)
+	}
+	t.Errorf("%d pixels differ from %s (tolerance %d), see %s.diff.png",
+		count, golden, tolerance, base)
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
@@ -0,0 +1,43 @@
+package enginetest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	gray := color.NRGBA{100, 100, 100, 255}
+	want := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	got := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			want.Set(x, y, gray)
+			got.Set(x, y, gray)
+		}
+	}
+	got.Set(1, 1, color.NRGBA{102, 100, 100, 255})
+	got.Set(2, 2, color.NRGBA{100, 100, 110, 255})
+
+	tests := []struct {
+		tolerance uint8
+		count     int
+	}{
+		{0, 2},
+		{2, 1},
+		{10, 0},
+	}
+	for _, test := range tests {
+		count, diff := Diff(got, want, test.tolerance)
+		if count != test.count {
+			t.Errorf("tolerance %d: %d pixels differ, want %d", test.tolerance, count, test.count)
+		}
+		if diff.Bounds() != want.Bounds() {
+			t.Errorf("diff image is %v, want %v", diff.Bounds(), want.Bounds())
+		}
+	}
+
+	if count, _ := Diff(image.NewRGBA(image.Rect(0, 0, 2, 2)), want, 255); count != 16 {
+		t.Errorf("different sizes: %d pixels differ, want all 16", count)
+	}
+}
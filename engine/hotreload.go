@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"log"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// How often Reload() looks at the files on disk.
+const reloadInterval = 500 * time.Millisecond
+
+// Reload is for development. When HotReload is on it checks (at most twice a
+// second) whether any texture's file changed on disk and, if so, uploads the
+// new image into the existing texture. The *sdl.Texture stays the same, so
+// sprites holding it draw the new image on the next frame.
+//
+// The new image must be the same size as the old one, sprite sheets are laid
+// out by size. Images that changed size are skipped with a log message.
+//
+// Loop.Run() calls Reload() every frame. SDL textures can only be touched from
+// the main thread, so call it from the game loop when not using Loop.
+func (a *Assets) Reload() {
+	if !a.HotReload {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(a.lastChecked) < reloadInterval {
+		return
+	}
+	a.lastChecked = now
+
+	for path, asset := range a.textures {
+		if asset.file == "" {
+			continue
+		}
+
+		info, err := os.Stat(asset.file)
+		if err != nil || !info.ModTime().After(asset.modTime) {
+			continue
+		}
+		asset.modTime = info.ModTime()
+
+		if err := reloadTexture(asset.texture, asset.file); err != nil {
+			log.Printf("assets: failed to reload %s: %s", path, err)
+			continue
+		}
+		log.Printf("assets: reloaded %s", path)
+	}
+}
+
+// reloadTexture replaces the pixels of t with the image in file.
+func reloadTexture(t *sdl.Texture, file string) error {
+	var format uint32
+	var w, h int
+	if sdl.QueryTexture(t, &format, nil, &w, &h) < 0 {
+		return sdlError("SDL_QueryTexture")
+	}
+
+	surface := img.Load(file)
+	if surface == nil {
+		return sdlError("IMG_Load")
+	}
+	defer surface.Free()
+
+	if int(surface.W) != w || int(surface.H) != h {
+		return errors.New(fmt.Sprintf("image changed size from %dx%d to %dx%d, restart to pick it up",
+			w, h, surface.W, surface.H))
+	}
+
+	converted := sdl.ConvertSurfaceFormat(surface, format, 0)
+	if converted == nil {
+		return sdlError("SDL_ConvertSurfaceFormat")
+	}
+	defer converted.Free()
+
+	pixels := converted.Pixels()
+	if t.Update(nil, unsafe.Pointer(&pixels[0]), int(converted.Pitch)) < 0 {
+		return sdlError("SDL_UpdateTexture")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAssetsReload(t *testing.T) {
+	w, dir := testAssets(t)
+	defer w.Cleanup()
+	a := w.Assets
+	r := w.Renderer()
+
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	file := filepath.Join(dir, "a.png")
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeImage(t, file, 4, 4, red, start)
+
+	texture, err := a.Texture("a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Release(texture)
+
+	// drawn is the color the texture draws now.
+	drawn := func() color.RGBA {
+		if err := Copy(r, texture, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		frame, err := w.ReadPixels()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return frame.RGBAAt(1, 1)
+	}
+
+	steps := []struct {
+		name      string
+		hotReload bool
+		recheck   bool // as if half a second went by since the last check
+		write     bool
+		size      int
+		c         color.RGBA
+		want      color.RGBA
+	}{
+		{"hot reload off", false, true, true, 4, blue, red},
+		{"changed file", true, true, false, 4, blue, blue},
+		{"checked too soon", true, false, true, 4, green, blue},
+		{"checked later", true, true, false, 4, green, green},
+		{"unchanged mtime", true, true, false, 4, green, green},
+		{"changed size", true, true, true, 8, red, green},
+	}
+	modTime := start
+	for _, step := range steps {
+		if step.write {
+			modTime = modTime.Add(time.Second)
+			writeImage(t, file, step.size, step.size, step.c, modTime)
+		}
+		a.HotReload = step.hotReload
+		if step.recheck {
+			a.lastChecked = time.Time{}
+		}
+
+		a.Reload()
+		if got := drawn(); got != step.want {
+			t.Errorf("%s: texture is %v, want %v", step.name, got, step.want)
+		}
+		if tex, _ := a.Texture("a.png"); tex != texture {
+			t.Errorf("%s: reloading replaced the texture", step.name)
+		}
+		a.Release(texture)
+	}
+
+	// A size change isn't retried every check
+	if a.textures["a.png"].modTime != modTime {
+		t.Errorf("mtime is %v after the size change, want %v", a.textures["a.png"].modTime, modTime)
+	}
+}
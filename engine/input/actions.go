@@ -0,0 +1,417 @@
+// Package input maps keys, key chords, mouse buttons and game controllers
+// to named actions, so game code asks for "jump" instead of checking
+// SCANCODE_SPACE. Bindings come from a JSON file the player can edit, or
+// rebind in game, and get saved back:
+//
+//	actions, err := input.NewActions(input.Config{
+//		"jump": {"Space", "Up", "Pad a"},
+//		"quit": {"Gui+Q", "Escape"},
+//	})
+//	actions.Load("input.json") // the player's bindings, if saved before
+//
+//	// Game.HandleEvent
+//	actions.HandleEvent(event)
+//
+//	// Game.Update
+//	if actions.Pressed("jump") { ... }
+//	actions.EndUpdate()
+package input
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Actions tracks the state of every action from the events it's fed.
+type Actions struct {
+	// Game controllers, opened as they're plugged in
+	Controllers *Controllers
+
+	// How far a controller axis has to be pushed, after the dead zone, to
+	// press the actions bound to it
+	PressThreshold float64
+
+	bindings map[string][]Binding
+	state    map[string]*actionState
+
+	// What each key, button or axis held down turned on, to turn it off
+	// again on release whatever the modifiers are by then
+	held map[source][]string
+
+	// How far each controller axis is pushed, each way
+	analog map[source]float64
+
+	// Modifiers held as of the last key event, for mouse buttons. Not
+	// sdl.GetModState() so replayed events press the same actions.
+	mod uint16
+
+	capture func(Binding)
+}
+
+// source is a key, button or axis direction, and the controller it's on
+type source struct {
+	binding Binding
+	pad     sdl.JoystickID
+}
+
+type actionState struct {
+	// Bindings holding the action down
+	down int
+
+	// Since the last EndUpdate
+	pressed, released bool
+}
+
+// ConflictError is returned when a binding is already used by other
+// actions.
+type ConflictError struct {
+	Binding Binding
+	Actions []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("input: %s is already bound to %s", e.Binding, strings.Join(e.Actions, ", "))
+}
+
+// NewActions creates the actions of defaults with their bindings.
+func NewActions(defaults Config) (*Actions, error) {
+	a := &Actions{
+		Controllers:    NewControllers(),
+		PressThreshold: 0.5,
+		bindings:       map[string][]Binding{},
+		state:          map[string]*actionState{},
+		held:           map[source][]string{},
+		analog:         map[source]float64{},
+	}
+	if err := a.Apply(defaults); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Names lists the actions, sorted.
+func (a *Actions) Names() []string {
+	names := make([]string, 0, len(a.bindings))
+	for name := range a.bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Bindings of an action.
+func (a *Actions) Bindings(action string) []Binding {
+	return append([]Binding(nil), a.bindings[action]...)
+}
+
+// Conflicts lists the actions other than action that b, or a binding
+// triggered by the same presses, is bound to.
+func (a *Actions) Conflicts(action string, b Binding) []string {
+	var names []string
+	for _, name := range a.Names() {
+		if name == action {
+			continue
+		}
+		for _, other := range a.bindings[name] {
+			if other.overlaps(b) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// Bind adds b to action. It fails with a *ConflictError if another action
+// already has it, Unbind it there first or use Rebind.
+func (a *Actions) Bind(action string, b Binding) error {
+	if conflicts := a.Conflicts(action, b); len(conflicts) > 0 {
+		return &ConflictError{Binding: b, Actions: conflicts}
+	}
+	a.add(action)
+	for _, existing := range a.bindings[action] {
+		if existing == b {
+			return nil
+		}
+	}
+	a.bindings[action] = append(a.bindings[action], b)
+	return nil
+}
+
+// Unbind removes b from action.
+func (a *Actions) Unbind(action string, b Binding) {
+	bindings := a.bindings[action]
+	for i, existing := range bindings {
+		if existing == b {
+			a.bindings[action] = append(bindings[:i:i], bindings[i+1:]...)
+			return
+		}
+	}
+}
+
+// Rebind replaces the binding old of action with b, or adds b when old
+// isn't bound to it. When action already has b, old is just removed. Same
+// conflict rules as Bind.
+func (a *Actions) Rebind(action string, old, b Binding) error {
+	if conflicts := a.Conflicts(action, b); len(conflicts) > 0 {
+		return &ConflictError{Binding: b, Actions: conflicts}
+	}
+	a.add(action)
+	if a.bound(action, b) {
+		if old != b {
+			a.Unbind(action, old)
+		}
+		return nil
+	}
+	bindings := a.bindings[action]
+	for i, existing := range bindings {
+		if existing == old {
+			bindings[i] = b
+			return nil
+		}
+	}
+	a.bindings[action] = append(bindings, b)
+	return nil
+}
+
+// Capture hands the next key or mouse button pressed (with the modifiers
+// held) to fn instead of triggering actions. Use it for "press a key for
+// jump" screens, then Rebind with what fn got.
+func (a *Actions) Capture(fn func(Binding)) {
+	a.capture = fn
+}
+
+// Capturing is true while waiting for Capture's key.
+func (a *Actions) Capturing() bool {
+	return a.capture != nil
+}
+
+func (a *Actions) add(action string) {
+	if _, ok := a.bindings[action]; !ok {
+		a.bindings[action] = nil
+	}
+	if _, ok := a.state[action]; !ok {
+		a.state[action] = &actionState{}
+	}
+}
+
+// Down is true while a binding of action is held.
+func (a *Actions) Down(action string) bool {
+	s, ok := a.state[action]
+	return ok && s.down > 0
+}
+
+// Pressed is true if action went down since the last EndUpdate.
+func (a *Actions) Pressed(action string) bool {
+	s, ok := a.state[action]
+	return ok && s.pressed
+}
+
+// Released is true if action went up since the last EndUpdate.
+func (a *Actions) Released(action string) bool {
+	s, ok := a.state[action]
+	return ok && s.released
+}
+
+// Value is how far action is pressed, 0 to 1. Keys and buttons are 0 or 1,
+// controller axes anything in between.
+func (a *Actions) Value(action string) float64 {
+	v := 0.0
+	for src, actions := range a.held {
+		if src.binding.Device != PadAxis && contains(actions, action) {
+			return 1
+		}
+	}
+	for src, amount := range a.analog {
+		if amount > v && a.bound(action, src.binding) {
+			v = amount
+		}
+	}
+	return v
+}
+
+// Axis is Value(positive) - Value(negative), e.g. Axis("move_left",
+// "move_right") is -1 to 1 with keys or a stick.
+func (a *Actions) Axis(negative, positive string) float64 {
+	return a.Value(positive) - a.Value(negative)
+}
+
+func (a *Actions) bound(action string, b Binding) bool {
+	for _, other := range a.bindings[action] {
+		if other == b {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EndUpdate forgets what was pressed and released. Call it at the end of
+// Game.Update so Pressed is true for one update per press.
+func (a *Actions) EndUpdate() {
+	for _, s := range a.state {
+		s.pressed, s.released = false, false
+	}
+}
+
+// HandleEvent updates the actions from keyboard, mouse and controller
+// events, others are ignored. Key repeats don't press actions again.
+func (a *Actions) HandleEvent(event sdl.Event) {
+	a.Controllers.HandleEvent(event)
+
+	switch t := event.(type) {
+	case *sdl.KeyDownEvent:
+		a.mod = t.Keysym.Mod
+		if t.Repeat == 0 {
+			a.press(source{binding: Key(t.Keysym.Scancode)}, t.Keysym.Mod)
+		}
+	case *sdl.KeyUpEvent:
+		a.mod = t.Keysym.Mod
+		a.release(source{binding: Key(t.Keysym.Scancode)})
+	case *sdl.MouseButtonEvent:
+		if t.State == sdl.PRESSED {
+			a.press(source{binding: Mouse(t.Button)}, a.mod)
+		} else {
+			a.release(source{binding: Mouse(t.Button)})
+		}
+
+	case *sdl.ControllerButtonEvent:
+		src := source{Pad(sdl.GameControllerButton(t.Button)), t.Which}
+		if t.State == sdl.PRESSED {
+			a.press(src, 0)
+		} else {
+			a.release(src)
+		}
+	case *sdl.ControllerAxisEvent:
+		a.axes(t.Which)
+	case *sdl.ControllerDeviceEvent:
+		if t.Type == sdl.CONTROLLERDEVICEREMOVED {
+			a.unplug(t.Which)
+		}
+	}
+}
+
+// axes updates the axis sources of a controller. All of them: with a
+// radial dead zone moving x changes y.
+func (a *Actions) axes(pad sdl.JoystickID) {
+	c, ok := a.Controllers.Get(pad)
+	if !ok {
+		return
+	}
+	for axis := sdl.GameControllerAxis(0); axis < sdl.CONTROLLER_AXIS_MAX; axis++ {
+		v := c.Axis(axis)
+		for _, sign := range []int8{-1, 1} {
+			src := source{Stick(axis, sign), pad}
+			amount := math.Max(0, v*float64(sign))
+			if amount == 0 {
+				delete(a.analog, src)
+			} else {
+				a.analog[src] = amount
+			}
+
+			_, held := a.held[src]
+			if amount >= a.PressThreshold && !held {
+				a.press(src, 0)
+			} else if amount < a.PressThreshold && held {
+				a.release(src)
+			}
+		}
+	}
+}
+
+// unplug lets go of everything held on a controller that's gone.
+func (a *Actions) unplug(pad sdl.JoystickID) {
+	for src := range a.held {
+		if src.binding.Device >= PadButton && src.pad == pad {
+			a.release(src)
+		}
+	}
+	for src := range a.analog {
+		if src.pad == pad {
+			delete(a.analog, src)
+		}
+	}
+}
+
+// press finds the actions bound to source with the modifiers in mod. Of
+// the bindings that fit, only the ones with the most modifiers count, so
+// Gui+Q doesn't also trigger what's bound to Q but Shift+W still walks.
+func (a *Actions) press(src source, mod uint16) {
+	if a.capture != nil {
+		b := src.binding
+		if b.Device == Keyboard && isModifierKey(b.Key) {
+			return
+		}
+		if b.Device == Keyboard || b.Device == MouseButton {
+			b.Mod = chordModifiers(mod)
+		}
+		// Held with nothing bound, so it doesn't go on to trigger what it
+		// was just bound to
+		a.held[src] = nil
+		fn := a.capture
+		a.capture = nil
+		fn(b)
+		return
+	}
+
+	if _, ok := a.held[src]; ok {
+		return
+	}
+
+	var actions []string
+	best := 0
+	for action, bindings := range a.bindings {
+		n := -1
+		for _, b := range bindings {
+			if b.matches(src.binding, mod) && b.Mod.groups() > n {
+				n = b.Mod.groups()
+			}
+		}
+		if n < best {
+			continue
+		}
+		if n > best {
+			best = n
+			actions = nil
+		}
+		actions = append(actions, action)
+	}
+
+	a.held[src] = actions
+	for _, action := range actions {
+		s := a.state[action]
+		if s.down == 0 {
+			s.pressed = true
+		}
+		s.down++
+	}
+}
+
+func (a *Actions) release(src source) {
+	actions, ok := a.held[src]
+	if !ok {
+		return
+	}
+	delete(a.held, src)
+	for _, action := range actions {
+		s, ok := a.state[action]
+		if !ok || s.down == 0 {
+			continue
+		}
+		s.down--
+		if s.down == 0 {
+			s.released = true
+		}
+	}
+}
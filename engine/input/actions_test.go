@@ -0,0 +1,222 @@
+package input
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"reflect"
+	"testing"
+)
+
+func keyDown(scancode uint32, mod uint16) sdl.Event {
+	return &sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Keysym: sdl.Keysym{Scancode: scancode, Mod: mod}}
+}
+
+func keyUp(scancode uint32, mod uint16) sdl.Event {
+	return &sdl.KeyUpEvent{Type: sdl.KEYUP, State: sdl.RELEASED, Keysym: sdl.Keysym{Scancode: scancode, Mod: mod}}
+}
+
+func mustActions(t *testing.T, c Config) *Actions {
+	a, err := NewActions(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestActionsModifiers(t *testing.T) {
+	a := mustActions(t, Config{
+		"up":   {"W"},
+		"save": {"Ctrl+S"},
+		"down": {"S"},
+		"quit": {"Gui+Q"},
+		"q":    {"Q"},
+		"all":  {"Ctrl+Shift+S"},
+	})
+
+	tests := []struct {
+		name    string
+		key     uint32
+		mod     uint16
+		pressed []string
+	}{
+		{"no modifiers", sdl.SCANCODE_W, 0, []string{"up"}},
+		{"walking while running", sdl.SCANCODE_W, sdl.KMOD_LSHIFT, []string{"up"}},
+		{"walking with every modifier", sdl.SCANCODE_W, sdl.KMOD_LSHIFT | sdl.KMOD_RCTRL | sdl.KMOD_LALT, []string{"up"}},
+		{"chord over its key", sdl.SCANCODE_Q, sdl.KMOD_LGUI, []string{"quit"}},
+		{"key without the chord", sdl.SCANCODE_Q, 0, []string{"q"}},
+		{"either side", sdl.SCANCODE_S, sdl.KMOD_RCTRL, []string{"save"}},
+		{"most modifiers win", sdl.SCANCODE_S, sdl.KMOD_LCTRL | sdl.KMOD_LSHIFT, []string{"all"}},
+		{"extra modifier on a chord", sdl.SCANCODE_S, sdl.KMOD_LCTRL | sdl.KMOD_LALT, []string{"save"}},
+		{"locks don't count", sdl.SCANCODE_Q, sdl.KMOD_LGUI | sdl.KMOD_CAPS | sdl.KMOD_NUM, []string{"quit"}},
+		{"shift without a shift chord", sdl.SCANCODE_S, sdl.KMOD_LSHIFT, []string{"down"}},
+	}
+	for _, test := range tests {
+		a.HandleEvent(keyDown(test.key, test.mod))
+		var pressed []string
+		for _, name := range a.Names() {
+			if a.Pressed(name) {
+				pressed = append(pressed, name)
+			}
+		}
+		if !reflect.DeepEqual(pressed, test.pressed) {
+			t.Errorf("%s: pressed %v, want %v", test.name, pressed, test.pressed)
+		}
+
+		// Released whatever is held by then
+		a.HandleEvent(keyUp(test.key, 0))
+		for _, name := range test.pressed {
+			if a.Down(name) || !a.Released(name) {
+				t.Errorf("%s: %s still down", test.name, name)
+			}
+		}
+		a.EndUpdate()
+	}
+}
+
+func TestActionsHeldByTwoKeys(t *testing.T) {
+	a := mustActions(t, Config{"jump": {"Space", "Up"}})
+	a.HandleEvent(keyDown(sdl.SCANCODE_SPACE, 0))
+	a.HandleEvent(keyDown(sdl.SCANCODE_UP, 0))
+	a.HandleEvent(keyUp(sdl.SCANCODE_SPACE, 0))
+	if !a.Down("jump") || a.Released("jump") {
+		t.Error("jump let go with Up still held")
+	}
+	a.HandleEvent(keyUp(sdl.SCANCODE_UP, 0))
+	if a.Down("jump") || !a.Released("jump") {
+		t.Error("jump still down")
+	}
+}
+
+func TestApplyConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		conflict []string
+	}{
+		{"same key", Config{"jump": {"Space"}, "fire": {"Space"}}, []string{"fire", "jump"}},
+		{"side and either side", Config{"save": {"Ctrl+S"}, "save_as": {"LCtrl+S"}}, []string{"save", "save_as"}},
+		{"chord and its key", Config{"save": {"Ctrl+S"}, "down": {"S"}}, nil},
+		{"other modifiers", Config{"save": {"Ctrl+S"}, "all": {"Ctrl+Shift+S"}}, nil},
+		{"mouse with modifiers", Config{"fire": {"Mouse Left"}, "alt_fire": {"Shift+Mouse Left"}}, nil},
+		{"same pad button", Config{"jump": {"Pad a"}, "fire": {"Pad a"}}, []string{"fire", "jump"}},
+		{"stick either way", Config{"left": {"Pad leftx-"}, "right": {"Pad leftx+"}}, nil},
+	}
+	for _, test := range tests {
+		_, err := NewActions(test.config)
+		if test.conflict == nil {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+			continue
+		}
+		conflict, ok := err.(*ConflictError)
+		if !ok {
+			t.Errorf("%s: got %v, want a conflict", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(conflict.Actions, test.conflict) {
+			t.Errorf("%s: conflict between %v, want %v", test.name, conflict.Actions, test.conflict)
+		}
+	}
+}
+
+// A failed Apply leaves the bindings as they were.
+func TestApplyKeepsBindingsOnError(t *testing.T) {
+	a := mustActions(t, Config{"jump": {"Space"}, "fire": {"Mouse Left"}})
+	before := a.Config()
+
+	if err := a.Apply(Config{"fire": {"Space"}}); err == nil {
+		t.Error("no conflict with jump")
+	}
+	if err := a.Apply(Config{"fire": {"Nope"}}); err == nil {
+		t.Error("no error for an unknown key")
+	}
+	if !reflect.DeepEqual(a.Config(), before) {
+		t.Errorf("bindings changed to %v", a.Config())
+	}
+
+	// Moving a key between actions in one go is fine
+	if err := a.Apply(Config{"jump": {"Up"}, "fire": {"Space"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Config{"jump": {"Up"}, "fire": {"Space"}}); !reflect.DeepEqual(a.Config(), want) {
+		t.Errorf("bindings %v, want %v", a.Config(), want)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	a := mustActions(t, Config{"jump": {"Space", "Up"}, "fire": {"Mouse Left"}})
+
+	if err := a.Rebind("jump", Key(sdl.SCANCODE_SPACE), Key(sdl.SCANCODE_W)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Config()["jump"], []string{"W", "Up"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump bound to %v, want %v", got, want)
+	}
+
+	err := a.Rebind("jump", Key(sdl.SCANCODE_W), Mouse(sdl.BUTTON_LEFT))
+	if conflict, ok := err.(*ConflictError); !ok || !reflect.DeepEqual(conflict.Actions, []string{"fire"}) {
+		t.Errorf("rebinding to fire's button: %v", err)
+	}
+
+	// Not bound before, added
+	if err := a.Rebind("fire", Key(sdl.SCANCODE_A), Key(sdl.SCANCODE_D)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Config()["fire"], []string{"Mouse Left", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fire bound to %v, want %v", got, want)
+	}
+
+	// Already bound: the old one goes, no second copy
+	if err := a.Rebind("jump", Key(sdl.SCANCODE_UP), Key(sdl.SCANCODE_W)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Config()["jump"], []string{"W"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump bound to %v, want %v", got, want)
+	}
+	if err := a.Rebind("jump", Key(sdl.SCANCODE_W), Key(sdl.SCANCODE_W)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Config()["jump"], []string{"W"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump bound to %v after rebinding W to itself, want %v", got, want)
+	}
+
+	// Nothing bound yet, a zero old adds
+	if err := a.Apply(Config{"jump": {}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Rebind("jump", Binding{}, Key(sdl.SCANCODE_W)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Config()["jump"], []string{"W"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump bound to %v, want %v", got, want)
+	}
+
+	// The new key presses jump, the old one doesn't
+	a.HandleEvent(keyDown(sdl.SCANCODE_SPACE, 0))
+	if a.Pressed("jump") {
+		t.Error("Space still jumps")
+	}
+	a.HandleEvent(keyDown(sdl.SCANCODE_W, 0))
+	if !a.Pressed("jump") {
+		t.Error("W doesn't jump")
+	}
+}
+
+func TestCapture(t *testing.T) {
+	a := mustActions(t, Config{"jump": {"Space"}})
+	var captured Binding
+	a.Capture(func(b Binding) { captured = b })
+
+	// Modifiers alone wait for the key
+	a.HandleEvent(keyDown(sdl.SCANCODE_LCTRL, sdl.KMOD_LCTRL))
+	if !a.Capturing() {
+		t.Fatal("captured a modifier")
+	}
+	a.HandleEvent(keyDown(sdl.SCANCODE_SPACE, sdl.KMOD_LCTRL))
+	if a.Capturing() || captured.String() != "Ctrl+Space" {
+		t.Errorf("captured %v", captured)
+	}
+	if a.Pressed("jump") {
+		t.Error("the captured press also jumped")
+	}
+}
@@ -0,0 +1,176 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"strconv"
+	"strings"
+)
+
+// Binding is one key, key chord, mouse button or controller button or axis
+// that triggers an action. Written out it reads "W", "Up", "Ctrl+S",
+// "Mouse Left", "Pad a" or "Pad leftx-", key names being SDL's scancode
+// names and controller names SDL's too. Mouse buttons can have modifiers
+// too ("Shift+Mouse Left").
+//
+// Unlike a Chord, a binding fires with more modifiers held than it has, so
+// holding Shift doesn't stop W from walking. When several bindings of the
+// key fit, only the ones with the most modifiers fire: Gui+Q quits without
+// also doing what Q does.
+type Binding struct {
+	Device Device
+
+	// Keyboard only, modifiers for mouse buttons too
+	Chord
+
+	// Mouse button (sdl.BUTTON_LEFT ...), controller button
+	// (sdl.CONTROLLER_BUTTON_A ...) or controller axis
+	// (sdl.CONTROLLER_AXIS_LEFTX ...)
+	Button uint8
+
+	// Controller axes: -1 or 1, which way the axis is pushed
+	Sign int8
+}
+
+// Device is where a binding's input comes from.
+type Device uint8
+
+const (
+	Keyboard Device = iota
+	MouseButton
+	PadButton
+	PadAxis
+)
+
+func Key(scancode uint32) Binding {
+	return Binding{Device: Keyboard, Chord: Chord{Key: scancode}}
+}
+
+func Mouse(button uint8) Binding {
+	return Binding{Device: MouseButton, Button: button}
+}
+
+func Pad(button sdl.GameControllerButton) Binding {
+	return Binding{Device: PadButton, Button: uint8(button)}
+}
+
+// Stick is an axis pushed one way, sign -1 for left or up (or 1 for
+// triggers).
+func Stick(axis sdl.GameControllerAxis, sign int8) Binding {
+	return Binding{Device: PadAxis, Button: uint8(axis), Sign: sign}
+}
+
+var mouseButtons = map[uint8]string{
+	sdl.BUTTON_LEFT:   "Left",
+	sdl.BUTTON_MIDDLE: "Middle",
+	sdl.BUTTON_RIGHT:  "Right",
+	sdl.BUTTON_X1:     "X1",
+	sdl.BUTTON_X2:     "X2",
+}
+
+func (b Binding) String() string {
+	switch b.Device {
+	case MouseButton:
+		name, ok := mouseButtons[b.Button]
+		if !ok {
+			name = strconv.Itoa(int(b.Button))
+		}
+		return modifiersString(b.Mod) + "Mouse " + name
+	case PadButton:
+		return "Pad " + sdl.GameControllerGetStringForButton(sdl.GameControllerButton(b.Button))
+	case PadAxis:
+		name := "Pad " + sdl.GameControllerGetStringForAxis(sdl.GameControllerAxis(b.Button))
+		if isTrigger(b.Button) {
+			return name
+		}
+		if b.Sign < 0 {
+			return name + "-"
+		}
+		return name + "+"
+	}
+	return b.Chord.String()
+}
+
+// ParseBinding reads a binding as written by String(). Modifier and mouse
+// names aren't case sensitive, and neither are SDL's key names.
+func ParseBinding(s string) (Binding, error) {
+	mod, rest := parseModifiers(s)
+	if len(rest) > 4 && strings.EqualFold(rest[:4], "pad ") {
+		return parsePad(s, strings.TrimSpace(rest[4:]))
+	}
+	if len(rest) <= 6 || !strings.EqualFold(rest[:6], "mouse ") {
+		c, err := ParseChord(s)
+		return Binding{Chord: c}, err
+	}
+
+	b := Binding{Device: MouseButton, Chord: Chord{Mod: mod}}
+	name := strings.TrimSpace(rest[6:])
+	for button, buttonName := range mouseButtons {
+		if strings.EqualFold(name, buttonName) {
+			b.Button = button
+			return b, nil
+		}
+	}
+	n, err := strconv.Atoi(name)
+	if err != nil || n <= 0 || n > 255 {
+		return Binding{}, errors.New(fmt.Sprintf("input: unknown mouse button in %q", s))
+	}
+	b.Button = uint8(n)
+	return b, nil
+}
+
+// parsePad reads the name of a controller button or axis, axes ending in
+// "+" or "-" except for triggers.
+func parsePad(s, name string) (Binding, error) {
+	if button := sdl.GameControllerGetButtonFromString(name); button != sdl.CONTROLLER_BUTTON_INVALID {
+		return Pad(button), nil
+	}
+
+	var sign int8 = 1
+	if strings.HasSuffix(name, "-") && !isTrigger(uint8(sdl.GameControllerGetAxisFromString(name[:len(name)-1]))) {
+		sign = -1
+	}
+	axis := sdl.GameControllerGetAxisFromString(strings.TrimRight(name, "+-"))
+	if axis == sdl.CONTROLLER_AXIS_INVALID {
+		return Binding{}, errors.New(fmt.Sprintf("input: unknown controller button or axis in %q", s))
+	}
+	if !isTrigger(uint8(axis)) && !strings.HasSuffix(name, "-") && !strings.HasSuffix(name, "+") {
+		return Binding{}, errors.New(fmt.Sprintf("input: %q needs a + or - for the way the stick is pushed", s))
+	}
+	return Stick(axis, sign), nil
+}
+
+func isTrigger(axis uint8) bool {
+	return axis == sdl.CONTROLLER_AXIS_TRIGGERLEFT || axis == sdl.CONTROLLER_AXIS_TRIGGERRIGHT
+}
+
+// matches is true when source, a key or a button just pressed, is b with
+// at least b's modifiers held in mod.
+func (b Binding) matches(source Binding, mod uint16) bool {
+	if b.Device != source.Device || b.Key != source.Key || b.Button != source.Button || b.Sign != source.Sign {
+		return false
+	}
+	return b.Device == PadButton || b.Device == PadAxis || b.Mod.within(mod)
+}
+
+// overlaps is true when some press triggers both b and o, like Ctrl+S and
+// LCtrl+S do. That's a conflict between actions.
+func (b Binding) overlaps(o Binding) bool {
+	if b.Device != o.Device || b.Key != o.Key || b.Button != o.Button || b.Sign != o.Sign {
+		return false
+	}
+	for _, g := range modifierGroups {
+		pair := ModifierKey(g.left | g.right)
+		if (b.Mod&pair == 0) != (o.Mod&pair == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isModifierKey is true for Ctrl, Shift, Alt and Gui keys, which make
+// chords rather than bindings on their own when capturing.
+func isModifierKey(scancode uint32) bool {
+	return scancode >= sdl.SCANCODE_LCTRL && scancode <= sdl.SCANCODE_RGUI
+}
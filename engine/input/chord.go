@@ -0,0 +1,237 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"strings"
+)
+
+// Chord is a key pressed with modifiers, written "Gui+Q" or
+// "Ctrl+Shift+S". "Ctrl", "Shift", "Alt" and "Gui" (or "Cmd") are either
+// side, "LCtrl", "RShift" and so on only that side.
+//
+// A chord matches when exactly its modifiers are held: Ctrl+S isn't
+// Ctrl+Shift+S, and S alone isn't Ctrl+S. Num Lock, Caps Lock and AltGr are
+// ignored.
+//
+// ParseChord chords are keys by where they are (scancodes), right for
+// movement keys on any layout. Shortcuts that go by the letter on the key,
+// like Cmd+Q, want ParseKeyChord instead.
+type Chord struct {
+	// Scancode of the key
+	Key uint32
+
+	// Keycode of the key, matched instead of Key when set
+	Sym sdl.Keycode
+
+	// Modifiers. Both bits of a pair (sdl.KMOD_CTRL) for either side, one
+	// (sdl.KMOD_LCTRL) for that side only.
+	Mod ModifierKey
+}
+
+// modifierGroups are the modifier pairs, in the order they're written in.
+var modifierGroups = []struct {
+	name        string
+	left, right uint16
+}{
+	{"Ctrl", sdl.KMOD_LCTRL, sdl.KMOD_RCTRL},
+	{"Shift", sdl.KMOD_LSHIFT, sdl.KMOD_RSHIFT},
+	{"Alt", sdl.KMOD_LALT, sdl.KMOD_RALT},
+	{"Gui", sdl.KMOD_LGUI, sdl.KMOD_RGUI},
+}
+
+// modifierAliases are other names people write for the Gui key
+var modifierAliases = map[string]string{
+	"cmd":   "gui",
+	"super": "gui",
+	"win":   "gui",
+}
+
+// ParseChord reads a chord as written by String(). Names aren't case
+// sensitive.
+func ParseChord(s string) (Chord, error) {
+	mod, rest := parseModifiers(s)
+	key := sdl.GetScancodeFromName(rest)
+	if key == sdl.SCANCODE_UNKNOWN {
+		return Chord{}, errors.New(fmt.Sprintf("input: unknown key in %q", s))
+	}
+	return Chord{Key: key, Mod: mod}, nil
+}
+
+// MustParseChord is ParseChord for chords known to be right, it panics on
+// errors:
+//
+//	var save = input.MustParseChord("Ctrl+S")
+func MustParseChord(s string) Chord {
+	c, err := ParseChord(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ParseKeyChord reads a chord whose key is a keycode name (SDL's key
+// names), so it matches the key with that letter whatever the keyboard
+// layout. On AZERTY "Gui+Q" is Cmd and the key labelled Q, where
+// ParseChord's would be Cmd+A.
+func ParseKeyChord(s string) (Chord, error) {
+	mod, rest := parseModifiers(s)
+	sym := sdl.GetKeyFromName(rest)
+	if sym == sdl.K_UNKNOWN {
+		return Chord{}, errors.New(fmt.Sprintf("input: unknown key in %q", s))
+	}
+	return Chord{Sym: sym, Mod: mod}, nil
+}
+
+// MustParseKeyChord is ParseKeyChord, panicking on errors:
+//
+//	var quit = input.MustParseKeyChord("Gui+Q")
+func MustParseKeyChord(s string) Chord {
+	c, err := ParseKeyChord(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// parseModifiers takes the modifiers off the front of s and returns them
+// with the rest, the key. Key names can have a "+" in them ("Keypad +") so
+// it stops at the first part that isn't a modifier.
+func parseModifiers(s string) (ModifierKey, string) {
+	var mod ModifierKey
+	rest := strings.TrimSpace(s)
+	for {
+		i := strings.Index(rest, "+")
+		if i <= 0 || i == len(rest)-1 {
+			return mod, rest
+		}
+		m, ok := modifierFromName(strings.TrimSpace(rest[:i]))
+		if !ok {
+			return mod, rest
+		}
+		mod |= m
+		rest = strings.TrimSpace(rest[i+1:])
+	}
+}
+
+func modifierFromName(name string) (ModifierKey, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := modifierAliases[name]; ok {
+		name = alias
+	}
+	for _, g := range modifierGroups {
+		group := strings.ToLower(g.name)
+		switch name {
+		case group:
+			return ModifierKey(g.left | g.right), true
+		case "l" + group:
+			return ModifierKey(g.left), true
+		case "r" + group:
+			return ModifierKey(g.right), true
+		}
+	}
+	return 0, false
+}
+
+// modifiersString writes mod the way chords do, "Ctrl+LShift+".
+func modifiersString(mod ModifierKey) string {
+	var s string
+	for _, g := range modifierGroups {
+		switch uint16(mod) & (g.left | g.right) {
+		case g.left | g.right:
+			s += g.name + "+"
+		case g.left:
+			s += "L" + g.name + "+"
+		case g.right:
+			s += "R" + g.name + "+"
+		}
+	}
+	return s
+}
+
+func (c Chord) String() string {
+	if c.Sym != sdl.K_UNKNOWN {
+		return modifiersString(c.Mod) + sdl.GetKeyName(c.Sym)
+	}
+	return modifiersString(c.Mod) + sdl.GetScancodeName(c.Key)
+}
+
+// Matches is true for key events (down or up) of the chord.
+func (c Chord) Matches(event sdl.Event) bool {
+	switch t := event.(type) {
+	case *sdl.KeyDownEvent:
+		return c.MatchesKeysym(t.Keysym)
+	case *sdl.KeyUpEvent:
+		return c.MatchesKeysym(t.Keysym)
+	}
+	return false
+}
+
+// MatchesKeysym is true when the key of k is the chord's, by keycode or by
+// scancode depending on how the chord was made, with the chord's modifiers
+// held and no others.
+func (c Chord) MatchesKeysym(k sdl.Keysym) bool {
+	if c.Sym != sdl.K_UNKNOWN {
+		return k.Sym == c.Sym && c.Mod.holds(k.Mod)
+	}
+	return c.MatchesKey(k.Scancode, k.Mod)
+}
+
+// MatchesKey is true when key is the chord's key and mod (Keysym.Mod or
+// sdl.GetModState()) holds the chord's modifiers and no others. It only
+// knows scancodes, use MatchesKeysym for keycode chords.
+func (c Chord) MatchesKey(key uint32, mod uint16) bool {
+	return c.Sym == sdl.K_UNKNOWN && key == c.Key && c.Mod.holds(mod)
+}
+
+// holds is true when mod has exactly the modifiers of m. A pair wanted
+// either side matches either or both, a side wanted needs that side.
+func (m ModifierKey) holds(mod uint16) bool {
+	for _, g := range modifierGroups {
+		pair := g.left | g.right
+		want, have := uint16(m)&pair, mod&pair
+		switch {
+		case want == 0 && have != 0:
+			return false
+		case want != 0 && have&want == 0:
+			return false
+		}
+	}
+	return true
+}
+
+// within is true when mod has at least the modifiers of m, others can be
+// held too.
+func (m ModifierKey) within(mod uint16) bool {
+	for _, g := range modifierGroups {
+		want := uint16(m) & (g.left | g.right)
+		if want != 0 && mod&want == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// groups counts the modifiers of m, either side or not.
+func (m ModifierKey) groups() int {
+	n := 0
+	for _, g := range modifierGroups {
+		if uint16(m)&(g.left|g.right) != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// chordModifiers is the modifiers held in mod, either side, the way a
+// captured binding records them.
+func chordModifiers(mod uint16) ModifierKey {
+	var m ModifierKey
+	for _, g := range modifierGroups {
+		if mod&(g.left|g.right) != 0 {
+			m |= ModifierKey(g.left | g.right)
+		}
+	}
+	return m
+}
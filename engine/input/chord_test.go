@@ -0,0 +1,140 @@
+package input
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"testing"
+)
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		s      string
+		key    uint32
+		mod    ModifierKey
+		String string
+	}{
+		{"Q", sdl.SCANCODE_Q, 0, "Q"},
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_GUI, "Gui+Q"},
+		{"cmd+q", sdl.SCANCODE_Q, sdl.KMOD_GUI, "Gui+Q"},
+		{"Ctrl+Shift+S", sdl.SCANCODE_S, sdl.KMOD_CTRL | sdl.KMOD_SHIFT, "Ctrl+Shift+S"},
+		// Written in the same order whatever order they came in
+		{"Shift + Ctrl + S", sdl.SCANCODE_S, sdl.KMOD_CTRL | sdl.KMOD_SHIFT, "Ctrl+Shift+S"},
+		{"LCtrl+RShift+S", sdl.SCANCODE_S, sdl.KMOD_LCTRL | sdl.KMOD_RSHIFT, "LCtrl+RShift+S"},
+		{"Keypad +", sdl.SCANCODE_KP_PLUS, 0, "Keypad +"},
+		{"Ctrl+Keypad +", sdl.SCANCODE_KP_PLUS, sdl.KMOD_CTRL, "Ctrl+Keypad +"},
+	}
+	for _, test := range tests {
+		c, err := ParseChord(test.s)
+		if err != nil {
+			t.Errorf("%q: %v", test.s, err)
+			continue
+		}
+		if c.Key != test.key || c.Mod != test.mod || c.Sym != sdl.K_UNKNOWN {
+			t.Errorf("%q: got key %d mod %v, want %d mod %v", test.s, c.Key, c.Mod, test.key, test.mod)
+		}
+		if c.String() != test.String {
+			t.Errorf("%q: String() = %q, want %q", test.s, c.String(), test.String)
+		}
+	}
+
+	for _, s := range []string{"", "Nope", "Hyper+Q", "Ctrl+"} {
+		if c, err := ParseChord(s); err == nil {
+			t.Errorf("%q: parsed as %v", s, c)
+		}
+	}
+}
+
+func TestChordMatches(t *testing.T) {
+	tests := []struct {
+		chord string
+		key   uint32
+		mod   uint16
+		match bool
+	}{
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI, true},
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_RGUI, true},
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI | sdl.KMOD_RGUI, true},
+		{"Gui+Q", sdl.SCANCODE_Q, 0, false},
+		{"Gui+Q", sdl.SCANCODE_A, sdl.KMOD_LGUI, false},
+		// Exactly the chord's modifiers
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI | sdl.KMOD_LSHIFT, false},
+		{"Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI, false},
+		{"Ctrl+Shift+S", sdl.SCANCODE_S, sdl.KMOD_LCTRL | sdl.KMOD_RSHIFT, true},
+		{"Ctrl+Shift+S", sdl.SCANCODE_S, sdl.KMOD_LCTRL, false},
+		// Sides
+		{"LGui+Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI, true},
+		{"LGui+Q", sdl.SCANCODE_Q, sdl.KMOD_RGUI, false},
+		// Lock keys are masked off
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI | sdl.KMOD_CAPS, true},
+		{"Gui+Q", sdl.SCANCODE_Q, sdl.KMOD_LGUI | sdl.KMOD_NUM | sdl.KMOD_CAPS | sdl.KMOD_MODE, true},
+		{"Q", sdl.SCANCODE_Q, sdl.KMOD_NUM, true},
+	}
+	for _, test := range tests {
+		c := MustParseChord(test.chord)
+		down := keyDown(test.key, test.mod)
+		up := keyUp(test.key, test.mod)
+		if c.Matches(down) != test.match || c.Matches(up) != test.match || c.MatchesKey(test.key, test.mod) != test.match {
+			t.Errorf("%s with %d and %v: match %v, want %v", test.chord, test.key, ModifierKey(test.mod), !test.match, test.match)
+		}
+	}
+
+	if MustParseChord("Q").Matches(&sdl.MouseButtonEvent{}) {
+		t.Error("matched a mouse event")
+	}
+}
+
+// A key chord goes by the letter, wherever the layout puts it.
+func TestKeyChord(t *testing.T) {
+	c, err := ParseKeyChord("Gui+Q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Sym != sdl.K_q || c.Mod != sdl.KMOD_GUI || c.String() != "Gui+Q" {
+		t.Errorf("got %+v, %q", c, c.String())
+	}
+
+	tests := []struct {
+		name   string
+		keysym sdl.Keysym
+		match  bool
+	}{
+		{"QWERTY", sdl.Keysym{Scancode: sdl.SCANCODE_Q, Sym: sdl.K_q, Mod: sdl.KMOD_LGUI}, true},
+		{"AZERTY", sdl.Keysym{Scancode: sdl.SCANCODE_A, Sym: sdl.K_q, Mod: sdl.KMOD_LGUI}, true},
+		{"Q's place on AZERTY", sdl.Keysym{Scancode: sdl.SCANCODE_Q, Sym: sdl.K_a, Mod: sdl.KMOD_LGUI}, false},
+		{"Caps Lock on", sdl.Keysym{Scancode: sdl.SCANCODE_Q, Sym: sdl.K_q, Mod: sdl.KMOD_RGUI | sdl.KMOD_CAPS}, true},
+		{"no Gui", sdl.Keysym{Scancode: sdl.SCANCODE_Q, Sym: sdl.K_q}, false},
+	}
+	for _, test := range tests {
+		if c.MatchesKeysym(test.keysym) != test.match {
+			t.Errorf("%s: match %v, want %v", test.name, !test.match, test.match)
+		}
+	}
+	if c.MatchesKey(sdl.SCANCODE_Q, sdl.KMOD_LGUI) {
+		t.Error("MatchesKey matched a keycode chord by scancode")
+	}
+
+	if _, err := ParseKeyChord("Gui+Nope"); err == nil {
+		t.Error("parsed an unknown key")
+	}
+}
+
+func TestModifierKeyString(t *testing.T) {
+	tests := []struct {
+		mod  uint16
+		want string
+	}{
+		{sdl.KMOD_NONE, "NONE"},
+		{sdl.KMOD_LSHIFT, "LSHIFT"},
+		{sdl.KMOD_LSHIFT | sdl.KMOD_LCTRL, "LSHIFT|LCTRL"},
+		{sdl.KMOD_GUI | sdl.KMOD_CAPS, "LGUI|RGUI|CAPS"},
+		{sdl.KMOD_NUM | 0x0004, "NUM|0x0004"},
+	}
+	for _, test := range tests {
+		if got := ModifierKey(test.mod).String(); got != test.want {
+			t.Errorf("%#04x: %q, want %q", test.mod, got, test.want)
+		}
+	}
+
+	if got := ModifierKey(sdl.KMOD_LCTRL | sdl.KMOD_CAPS | sdl.KMOD_NUM | sdl.KMOD_MODE).WithoutLocks(); got != sdl.KMOD_LCTRL {
+		t.Errorf("WithoutLocks() = %v, want LCTRL", got)
+	}
+}
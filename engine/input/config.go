@@ -0,0 +1,117 @@
+package input
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+)
+
+// Config is what gets saved: the bindings of every action, written out.
+//
+//	{
+//	  "jump": ["Space", "Up"],
+//	  "quit": ["Gui+Q", "Escape"],
+//	  "fire": ["Mouse Left"]
+//	}
+type Config map[string][]string
+
+// Config writes out the current bindings.
+func (a *Actions) Config() Config {
+	c := Config{}
+	for name, bindings := range a.bindings {
+		list := []string{}
+		for _, b := range bindings {
+			list = append(list, b.String())
+		}
+		c[name] = list
+	}
+	return c
+}
+
+// Apply replaces the bindings of the actions in c, and adds the actions
+// that are new. Nothing changes if a binding doesn't parse or would end up
+// on two actions.
+func (a *Actions) Apply(c Config) error {
+	parsed := map[string][]Binding{}
+	for name, list := range c {
+		bindings := []Binding{}
+		for _, s := range list {
+			b, err := ParseBinding(s)
+			if err != nil {
+				return err
+			}
+			bindings = append(bindings, b)
+		}
+		parsed[name] = bindings
+	}
+
+	// Check the bindings as they'll be once applied
+	names := a.Names()
+	for name := range parsed {
+		if _, ok := a.bindings[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, b := range parsed[name] {
+			var owners []string
+			for _, other := range names {
+				bindings, ok := parsed[other]
+				if !ok {
+					bindings = a.bindings[other]
+				}
+				for _, ob := range bindings {
+					if ob.overlaps(b) {
+						owners = append(owners, other)
+						break
+					}
+				}
+			}
+			if len(owners) > 1 {
+				return &ConflictError{Binding: b, Actions: owners}
+			}
+		}
+	}
+
+	for name, bindings := range parsed {
+		a.add(name)
+		a.bindings[name] = bindings
+	}
+	return nil
+}
+
+// Load applies the bindings saved at path over the current ones. A missing
+// file isn't an error, there's nothing saved yet. Actions the game doesn't
+// have (anymore) are skipped.
+func (a *Actions) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	for name := range c {
+		if _, ok := a.bindings[name]; !ok {
+			log.Printf("input: %s: skipping unknown action %q", path, name)
+			delete(c, name)
+		}
+	}
+	return a.Apply(c)
+}
+
+// Save writes the current bindings to path.
+func (a *Actions) Save(path string) error {
+	data, err := json.MarshalIndent(a.Config(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,236 @@
+package input
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"log"
+	"math"
+)
+
+// Controllers keeps track of the game controllers (gamepads with SDL's
+// standard Xbox-like layout) plugged in. They're opened and closed as the
+// CONTROLLERDEVICEADDED and CONTROLLERDEVICEREMOVED events come in, which
+// SDL also sends at startup for the controllers already there. The ones
+// still open are closed by CloseControllers (Window.Cleanup calls it).
+//
+// Actions has one and feeds it its events, use Actions.Controllers rather
+// than making your own.
+type Controllers struct {
+	// How far a stick has to be pushed before it counts, as a fraction of
+	// the way. Radial: the same in every direction. Past it the value goes
+	// smoothly from 0 to 1.
+	DeadZone float64
+
+	// Same for the triggers
+	TriggerDeadZone float64
+
+	// Called when a controller is plugged in and opened, and when it's
+	// unplugged
+	OnAdded, OnRemoved func(c *Controller)
+
+	pads map[sdl.JoystickID]*Controller
+}
+
+// Controller is a gamepad, or events that look like they come from one.
+type Controller struct {
+	// SDL's instance id, the Which of its events
+	ID   sdl.JoystickID
+	Name string
+
+	owner   *Controllers
+	pad     *sdl.GameController
+	buttons [sdl.CONTROLLER_BUTTON_MAX]bool
+	axes    [sdl.CONTROLLER_AXIS_MAX]int16
+}
+
+// Every Controllers with controllers in it, for CloseControllers.
+var inUse = map[*Controllers]bool{}
+
+// Set while events come from a recording, see SetReplaying.
+var replaying bool
+
+// CloseControllers closes the controllers of every Controllers. Call it
+// before sdl.Quit, Window.Cleanup does.
+func CloseControllers() {
+	for cs := range inUse {
+		cs.Close()
+	}
+}
+
+// SetReplaying tells Controllers whether events are played back from a
+// recording. A replayed CONTROLLERDEVICEADDED names a device index on the
+// machine it was recorded on, nothing is opened for it: the controller is
+// made up from its button and axis events, like a pushed one. Loop.Run sets
+// it while playing a Replay.
+func SetReplaying(on bool) {
+	replaying = on
+}
+
+func NewControllers() *Controllers {
+	return &Controllers{
+		DeadZone:        0.25,
+		TriggerDeadZone: 0.1,
+		pads:            map[sdl.JoystickID]*Controller{},
+	}
+}
+
+// All lists the controllers plugged in.
+func (cs *Controllers) All() []*Controller {
+	pads := make([]*Controller, 0, len(cs.pads))
+	for _, c := range cs.pads {
+		pads = append(pads, c)
+	}
+	return pads
+}
+
+// Get finds a controller by instance id.
+func (cs *Controllers) Get(id sdl.JoystickID) (*Controller, bool) {
+	c, ok := cs.pads[id]
+	return c, ok
+}
+
+// HandleEvent opens and closes controllers and tracks their buttons and
+// axes. Button and axis events from a controller it didn't see being added
+// (pushed with sdl.PushEvent, or replayed) make a controller with no
+// device behind it. See SetReplaying for added events from recordings.
+func (cs *Controllers) HandleEvent(event sdl.Event) {
+	switch t := event.(type) {
+	case *sdl.ControllerDeviceEvent:
+		switch t.Type {
+		case sdl.CONTROLLERDEVICEADDED:
+			// Which is the device index here, not an instance id
+			if !replaying {
+				cs.open(int(t.Which))
+			}
+		case sdl.CONTROLLERDEVICEREMOVED:
+			cs.close(t.Which)
+		}
+
+	case *sdl.ControllerButtonEvent:
+		if int(t.Button) < len(cs.get(t.Which).buttons) {
+			cs.get(t.Which).buttons[t.Button] = t.State == sdl.PRESSED
+		}
+
+	case *sdl.ControllerAxisEvent:
+		if int(t.Axis) < len(cs.get(t.Which).axes) {
+			cs.get(t.Which).axes[t.Axis] = t.Value
+		}
+	}
+}
+
+// Close closes every controller. CloseControllers does it for all of them
+// at exit.
+func (cs *Controllers) Close() {
+	for id := range cs.pads {
+		cs.close(id)
+	}
+	delete(inUse, cs)
+}
+
+func (cs *Controllers) open(index int) {
+	if !sdl.IsGameController(index) {
+		// A joystick SDL doesn't know the layout of
+		return
+	}
+	pad := sdl.GameControllerOpen(index)
+	if pad == nil {
+		log.Printf("input: could not open controller %d: %v", index, sdl.GetError())
+		return
+	}
+
+	id := pad.GetJoystick().InstanceID()
+	if _, ok := cs.pads[id]; ok {
+		// Already open, SDL counts it twice at startup sometimes
+		pad.Close()
+		return
+	}
+	c := &Controller{ID: id, Name: pad.Name(), owner: cs, pad: pad}
+	cs.pads[id] = c
+	inUse[cs] = true
+	if cs.OnAdded != nil {
+		cs.OnAdded(c)
+	}
+}
+
+func (cs *Controllers) close(id sdl.JoystickID) {
+	c, ok := cs.pads[id]
+	if !ok {
+		return
+	}
+	delete(cs.pads, id)
+	if c.pad != nil {
+		c.pad.Close()
+		c.pad = nil
+	}
+	if cs.OnRemoved != nil {
+		cs.OnRemoved(c)
+	}
+}
+
+// get is the controller id, made up if it wasn't added.
+func (cs *Controllers) get(id sdl.JoystickID) *Controller {
+	c, ok := cs.pads[id]
+	if !ok {
+		c = &Controller{ID: id, Name: "virtual controller", owner: cs}
+		cs.pads[id] = c
+		inUse[cs] = true
+	}
+	return c
+}
+
+// Button is true while the button (sdl.CONTROLLER_BUTTON_A ...) is down.
+func (c *Controller) Button(button sdl.GameControllerButton) bool {
+	if button < 0 || int(button) >= len(c.buttons) {
+		return false
+	}
+	return c.buttons[button]
+}
+
+// Axis is where an axis (sdl.CONTROLLER_AXIS_LEFTX ...) is, dead zone
+// applied: -1 to 1 for sticks, up and left being negative, 0 to 1 for
+// triggers.
+func (c *Controller) Axis(axis sdl.GameControllerAxis) float64 {
+	switch axis {
+	case sdl.CONTROLLER_AXIS_LEFTX, sdl.CONTROLLER_AXIS_RIGHTX:
+		x, _ := c.stick(axis, axis+1)
+		return x
+	case sdl.CONTROLLER_AXIS_LEFTY, sdl.CONTROLLER_AXIS_RIGHTY:
+		_, y := c.stick(axis-1, axis)
+		return y
+	case sdl.CONTROLLER_AXIS_TRIGGERLEFT, sdl.CONTROLLER_AXIS_TRIGGERRIGHT:
+		return deadZone(axisValue(c.axes[axis]), c.owner.TriggerDeadZone)
+	}
+	return 0
+}
+
+// LeftStick is the left stick's x, y with the dead zone applied.
+func (c *Controller) LeftStick() (float64, float64) {
+	return c.stick(sdl.CONTROLLER_AXIS_LEFTX, sdl.CONTROLLER_AXIS_LEFTY)
+}
+
+func (c *Controller) RightStick() (float64, float64) {
+	return c.stick(sdl.CONTROLLER_AXIS_RIGHTX, sdl.CONTROLLER_AXIS_RIGHTY)
+}
+
+// stick applies the dead zone to the length of the stick's x, y rather
+// than to each axis, so diagonals aren't stickier than straight lines.
+func (c *Controller) stick(xAxis, yAxis sdl.GameControllerAxis) (float64, float64) {
+	x, y := axisValue(c.axes[xAxis]), axisValue(c.axes[yAxis])
+	length := math.Hypot(x, y)
+	if length == 0 {
+		return 0, 0
+	}
+	scaled := deadZone(math.Min(length, 1), c.owner.DeadZone)
+	return x / length * scaled, y / length * scaled
+}
+
+func axisValue(v int16) float64 {
+	return math.Max(-1, float64(v)/32767)
+}
+
+// deadZone maps zone .. 1 to 0 .. 1 and anything under zone to 0.
+func deadZone(v, zone float64) float64 {
+	if v < zone {
+		return 0
+	}
+	return (v - zone) / (1 - zone)
+}
@@ -0,0 +1,238 @@
+package input
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"math"
+	"testing"
+)
+
+func padButton(which sdl.JoystickID, button sdl.GameControllerButton, down bool) sdl.Event {
+	e := &sdl.ControllerButtonEvent{Type: sdl.CONTROLLERBUTTONUP, Which: which, Button: uint8(button), State: sdl.RELEASED}
+	if down {
+		e.Type, e.State = sdl.CONTROLLERBUTTONDOWN, sdl.PRESSED
+	}
+	return e
+}
+
+// padAxis pushes axis to v, -1 to 1.
+func padAxis(which sdl.JoystickID, axis sdl.GameControllerAxis, v float64) sdl.Event {
+	return &sdl.ControllerAxisEvent{Type: sdl.CONTROLLERAXISMOTION, Which: which, Axis: uint8(axis), Value: int16(v * 32767)}
+}
+
+func nearly(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestControllerDeadZone(t *testing.T) {
+	diagonal := math.Sqrt(0.5)
+	// 0.2 each way is 0.28 out, just past the 0.25 zone
+	past := (math.Hypot(0.2, 0.2) - 0.25) / 0.75 * diagonal
+	tests := []struct {
+		name             string
+		x, y             float64
+		wantX, wantY     float64
+		trigger, wantTrg float64
+	}{
+		{"centered", 0, 0, 0, 0, 0, 0},
+		{"inside on one axis", 0.2, 0, 0, 0, 0, 0},
+		{"inside on each axis, not together", 0.2, 0.2, past, past, 0, 0},
+		{"edge of the zone", 0, -0.25, 0, 0, 0, 0},
+		{"halfway past the zone", 0.625, 0, 0.5, 0, 0, 0},
+		{"all the way", -1, 0, -1, 0, 0, 0},
+		{"corner, clamped to the circle", 1, 1, diagonal, diagonal, 0, 0},
+		{"trigger inside its zone", 0, 0, 0, 0, 0.05, 0},
+		{"trigger halfway", 0, 0, 0, 0, 0.55, 0.5},
+	}
+	for _, test := range tests {
+		cs := NewControllers()
+		cs.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTX, test.x))
+		cs.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTY, test.y))
+		cs.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_TRIGGERLEFT, test.trigger))
+		c, _ := cs.Get(1)
+
+		x, y := c.LeftStick()
+		if !nearly(x, test.wantX) || !nearly(y, test.wantY) {
+			t.Errorf("%s: stick at %.3f, %.3f, want %.3f, %.3f", test.name, x, y, test.wantX, test.wantY)
+		}
+		if x != c.Axis(sdl.CONTROLLER_AXIS_LEFTX) || y != c.Axis(sdl.CONTROLLER_AXIS_LEFTY) {
+			t.Errorf("%s: Axis disagrees with LeftStick", test.name)
+		}
+		if trg := c.Axis(sdl.CONTROLLER_AXIS_TRIGGERLEFT); !nearly(trg, test.wantTrg) {
+			t.Errorf("%s: trigger at %.3f, want %.3f", test.name, trg, test.wantTrg)
+		}
+	}
+}
+
+func TestActionsStick(t *testing.T) {
+	a := mustActions(t, Config{"left": {"Left", "Pad leftx-"}, "right": {"Right", "Pad leftx+"}, "down": {"Pad lefty+"}})
+
+	steps := []struct {
+		name     string
+		x        float64
+		axis     float64
+		leftDown bool
+	}{
+		{"in the dead zone", -0.2, 0, false},
+		// 0.4 past the dead zone, under the 0.5 threshold
+		{"under the threshold", -0.55, -0.4, false},
+		{"over the threshold", -0.7, -0.6, true},
+		{"all the way", -1, -1, true},
+		{"back under", -0.55, -0.4, false},
+		{"other way", 1, 1, false},
+	}
+	for _, step := range steps {
+		a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTX, step.x))
+		if got := a.Axis("left", "right"); !nearly(got, step.axis) {
+			t.Errorf("%s: axis at %.3f, want %.3f", step.name, got, step.axis)
+		}
+		if a.Down("left") != step.leftDown {
+			t.Errorf("%s: left down %v, want %v", step.name, a.Down("left"), step.leftDown)
+		}
+	}
+	if !a.Down("right") || !a.Pressed("right") || !a.Released("left") {
+		t.Error("right not pressed, or left not let go")
+	}
+
+	// Radial: a little down doesn't count alone, but tilts the stick once
+	// it's pushed right
+	a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTX, 0))
+	a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTY, 0.2))
+	if a.Value("down") != 0 {
+		t.Errorf("down at %.3f in the dead zone", a.Value("down"))
+	}
+	a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTX, 1))
+	if v := a.Value("down"); !nearly(v, 0.2/math.Hypot(1, 0.2)) {
+		t.Errorf("down at %.3f with the stick right", v)
+	}
+
+	// A key is all the way
+	a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_LEFTX, -0.55))
+	a.HandleEvent(keyDown(sdl.SCANCODE_LEFT, 0))
+	if a.Value("left") != 1 {
+		t.Errorf("left at %.3f with the key down", a.Value("left"))
+	}
+}
+
+func TestActionsPressThreshold(t *testing.T) {
+	a := mustActions(t, Config{"fire": {"Pad righttrigger"}})
+	a.PressThreshold = 0.9
+
+	a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_TRIGGERRIGHT, 0.8))
+	if a.Down("fire") || a.Value("fire") == 0 {
+		t.Errorf("fire down %v at %.3f", a.Down("fire"), a.Value("fire"))
+	}
+	a.HandleEvent(padAxis(1, sdl.CONTROLLER_AXIS_TRIGGERRIGHT, 1))
+	if !a.Down("fire") || !a.Pressed("fire") {
+		t.Error("fire not pressed all the way")
+	}
+}
+
+func TestActionsUnplug(t *testing.T) {
+	a := mustActions(t, Config{"jump": {"Space", "Pad a"}, "left": {"Pad leftx-"}})
+	var removed []sdl.JoystickID
+	a.Controllers.OnRemoved = func(c *Controller) { removed = append(removed, c.ID) }
+
+	a.HandleEvent(padButton(3, sdl.CONTROLLER_BUTTON_A, true))
+	a.HandleEvent(padAxis(3, sdl.CONTROLLER_AXIS_LEFTX, -1))
+	// Another controller and the keyboard hold on
+	a.HandleEvent(padButton(4, sdl.CONTROLLER_BUTTON_A, true))
+	a.HandleEvent(keyDown(sdl.SCANCODE_SPACE, 0))
+	a.EndUpdate()
+
+	a.HandleEvent(&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEREMOVED, Which: 3})
+	if len(removed) != 1 || removed[0] != 3 {
+		t.Errorf("removed %v, want [3]", removed)
+	}
+	if _, ok := a.Controllers.Get(3); ok {
+		t.Error("controller 3 still there")
+	}
+	if a.Down("left") || !a.Released("left") || a.Value("left") != 0 {
+		t.Errorf("left still down at %.3f", a.Value("left"))
+	}
+	if !a.Down("jump") || a.Released("jump") {
+		t.Error("jump let go with it still held elsewhere")
+	}
+
+	a.HandleEvent(keyUp(sdl.SCANCODE_SPACE, 0))
+	a.HandleEvent(&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEREMOVED, Which: 4})
+	if a.Down("jump") || !a.Released("jump") {
+		t.Error("jump still down with everything unplugged")
+	}
+}
+
+// Events from a controller that was never added, e.g. pushed or replayed,
+// make a virtual one.
+func TestControllerUnknownWhich(t *testing.T) {
+	a := mustActions(t, Config{"jump": {"Pad a"}})
+	a.HandleEvent(padButton(7, sdl.CONTROLLER_BUTTON_A, true))
+
+	c, ok := a.Controllers.Get(7)
+	if !ok || c.ID != 7 || !c.Button(sdl.CONTROLLER_BUTTON_A) {
+		t.Fatalf("got %+v, %v", c, ok)
+	}
+	if !a.Pressed("jump") {
+		t.Error("jump not pressed")
+	}
+
+	// Out of range buttons and axes are ignored
+	a.HandleEvent(&sdl.ControllerButtonEvent{Type: sdl.CONTROLLERBUTTONDOWN, Which: 7, Button: 200, State: sdl.PRESSED})
+	a.HandleEvent(&sdl.ControllerAxisEvent{Type: sdl.CONTROLLERAXISMOTION, Which: 7, Axis: 200, Value: 32767})
+	if c.Button(200) || c.Axis(200) != 0 || len(a.Controllers.All()) != 1 {
+		t.Error("out of range input counted")
+	}
+
+	a.HandleEvent(padButton(7, sdl.CONTROLLER_BUTTON_A, false))
+	if a.Down("jump") || c.Button(sdl.CONTROLLER_BUTTON_A) {
+		t.Error("jump still down")
+	}
+}
+
+// A replayed added event doesn't open anything, the recorded controller is
+// made up from its events and removed like a real one.
+func TestControllersReplaying(t *testing.T) {
+	SetReplaying(true)
+	defer SetReplaying(false)
+
+	a := mustActions(t, Config{"jump": {"Pad a"}})
+	var added, removed []sdl.JoystickID
+	a.Controllers.OnAdded = func(c *Controller) { added = append(added, c.ID) }
+	a.Controllers.OnRemoved = func(c *Controller) { removed = append(removed, c.ID) }
+
+	a.HandleEvent(&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEADDED, Which: 0})
+	if len(added) != 0 || len(a.Controllers.All()) != 0 {
+		t.Fatalf("added %v, controllers %v", added, a.Controllers.All())
+	}
+
+	a.HandleEvent(padButton(3, sdl.CONTROLLER_BUTTON_A, true))
+	c, ok := a.Controllers.Get(3)
+	if !ok || c.pad != nil || !a.Pressed("jump") {
+		t.Fatalf("got %+v, %v, jump pressed %v", c, ok, a.Pressed("jump"))
+	}
+
+	a.HandleEvent(&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEREMOVED, Which: 3})
+	if len(removed) != 1 || removed[0] != 3 || len(a.Controllers.All()) != 0 || a.Down("jump") {
+		t.Errorf("removed %v, controllers %v, jump down %v", removed, a.Controllers.All(), a.Down("jump"))
+	}
+}
+
+func TestCloseControllers(t *testing.T) {
+	var removed []sdl.JoystickID
+	var all []*Controllers
+	for i := 0; i < 2; i++ {
+		cs := NewControllers()
+		cs.OnRemoved = func(c *Controller) { removed = append(removed, c.ID) }
+		cs.HandleEvent(padButton(sdl.JoystickID(i), sdl.CONTROLLER_BUTTON_A, true))
+		all = append(all, cs)
+	}
+	unused := NewControllers()
+
+	CloseControllers()
+	for i, cs := range all {
+		if len(cs.All()) != 0 || inUse[cs] {
+			t.Errorf("Controllers %d still has %v", i, cs.All())
+		}
+	}
+	if len(removed) != 2 || inUse[unused] || len(inUse) != 0 {
+		t.Errorf("removed %v, %d Controllers left in use", removed, len(inUse))
+	}
+}
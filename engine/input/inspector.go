@@ -0,0 +1,362 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"io"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Inspector prints the SDL events it's fed, one line each, decoded into
+// named fields: key and scancode names, modifiers as a ModifierKey, button
+// and axis names ... instead of raw numbers.
+//
+// engine.Loop has one, off until its Toggle chord (F12) is pressed.
+type Inspector struct {
+	Enabled bool
+
+	// Text lines, or JSON lines to pipe into jq or a file
+	JSON bool
+
+	// Turns the inspector on and off. That key press isn't printed or
+	// passed on.
+	Toggle Chord
+
+	Out io.Writer
+
+	// Event types to print, see Filter. Everything when empty.
+	types map[string]bool
+}
+
+// EventInfo is an event decoded for reading.
+type EventInfo struct {
+	// KEYDOWN, MOUSEMOTION ..., or the number for types SDL doesn't name
+	Type string
+
+	// key, mouse, controller ..., what Filter takes besides type names
+	Category string
+
+	Timestamp uint32
+
+	// In the order of the event's struct
+	Fields []EventField
+}
+
+type EventField struct {
+	Name  string
+	Value interface{}
+}
+
+func NewInspector(out io.Writer) *Inspector {
+	return &Inspector{
+		Toggle: Chord{Key: sdl.SCANCODE_F12},
+		Out:    out,
+		types:  map[string]bool{},
+	}
+}
+
+// Filter only prints events of the types or categories given, e.g.
+// Filter("key", "MOUSEBUTTONDOWN"). Case doesn't matter. No arguments prints
+// everything again.
+func (in *Inspector) Filter(types ...string) error {
+	filter := map[string]bool{}
+	for _, t := range types {
+		name := strings.ToUpper(strings.TrimSpace(t))
+		if !knownEventType(name) {
+			return errors.New(fmt.Sprintf("unknown event type or category %q", t))
+		}
+		filter[name] = true
+	}
+	in.types = filter
+	return nil
+}
+
+// HandleEvent prints event when enabled and it passes the filter. True when
+// it was the toggle chord, which the caller should then drop.
+func (in *Inspector) HandleEvent(event sdl.Event) bool {
+	if in.Toggled(event) {
+		return true
+	}
+	if in.Enabled {
+		in.Print(event)
+	}
+	return false
+}
+
+// Toggled turns the inspector on or off on a press of the toggle chord.
+// True for every event of the chord, repeats and key up included.
+func (in *Inspector) Toggled(event sdl.Event) bool {
+	switch t := event.(type) {
+	case *sdl.KeyDownEvent:
+		if !in.Toggle.Matches(t) {
+			return false
+		}
+		if t.Repeat == 0 {
+			in.Enabled = !in.Enabled
+			if in.Enabled {
+				log.Printf("Event inspector on, %s to turn it off", in.Toggle)
+			} else {
+				log.Println("Event inspector off")
+			}
+		}
+		return true
+	case *sdl.KeyUpEvent:
+		return in.Toggle.Matches(t)
+	}
+	return false
+}
+
+// Print writes event if it passes the filter, enabled or not.
+func (in *Inspector) Print(event sdl.Event) {
+	info := DecodeEvent(event)
+	if len(in.types) > 0 && !in.types[info.Type] && !in.types[strings.ToUpper(info.Category)] {
+		return
+	}
+
+	var line []byte
+	if in.JSON {
+		var err error
+		if line, err = info.MarshalJSON(); err != nil {
+			log.Println("inspector:", err)
+			return
+		}
+	} else {
+		line = []byte(info.String())
+	}
+	line = append(line, '\n')
+	if _, err := in.Out.Write(line); err != nil {
+		log.Println("inspector:", err)
+	}
+}
+
+// DecodeEvent names an event's type and fields. Any event struct works,
+// fields it doesn't know better are shown as they are.
+func DecodeEvent(event sdl.Event) EventInfo {
+	v := reflect.Indirect(reflect.ValueOf(event))
+	if v.Kind() != reflect.Struct {
+		return EventInfo{Type: "UNKNOWN", Category: "unknown"}
+	}
+
+	var info EventInfo
+	var typ uint32
+	if f := v.FieldByName("Type"); f.IsValid() && f.Kind() == reflect.Uint32 {
+		typ = uint32(f.Uint())
+	}
+	info.Type, info.Category = eventTypeName(typ)
+	if f := v.FieldByName("Timestamp"); f.IsValid() && f.Kind() == reflect.Uint32 {
+		info.Timestamp = uint32(f.Uint())
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" || field.Name == "Type" || field.Name == "Timestamp" {
+			continue
+		}
+		info.Fields = append(info.Fields, decodeField(info.Category, field.Name, v.Field(i))...)
+	}
+	return info
+}
+
+// decodeField turns a field into readable fields, most into just one.
+func decodeField(category, name string, v reflect.Value) []EventField {
+	one := func(value interface{}) []EventField {
+		return []EventField{{strings.ToLower(name), value}}
+	}
+
+	switch {
+	case name == "Keysym":
+		k := v.Interface().(sdl.Keysym)
+		return []EventField{
+			{"key", sdl.GetKeyName(k.Sym)},
+			{"scancode", sdl.GetScancodeName(k.Scancode)},
+			{"mod", ModifierKey(k.Mod).String()},
+		}
+	case name == "State" && v.Kind() == reflect.Uint8:
+		if v.Uint() == sdl.PRESSED {
+			return one("pressed")
+		}
+		return one("released")
+	case name == "Button" && category == "mouse":
+		if button, ok := mouseButtons[uint8(v.Uint())]; ok {
+			return one(button)
+		}
+		return one(v.Uint())
+	case name == "Button" && category == "controller":
+		return one(sdl.GameControllerGetStringForButton(sdl.GameControllerButton(v.Uint())))
+	case name == "Axis" && category == "controller":
+		return one(sdl.GameControllerGetStringForAxis(sdl.GameControllerAxis(v.Uint())))
+	case name == "Event" && category == "window":
+		return one(windowEventName(uint8(v.Uint())))
+	case name == "Text" && v.Kind() == reflect.Array:
+		text := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(text), v)
+		if end := bytes.IndexByte(text, 0); end >= 0 {
+			text = text[:end]
+		}
+		return one(string(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return one(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return one(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return one(v.Float())
+	case reflect.Bool, reflect.String:
+		return one(v.Interface())
+	}
+	// Pointers into C memory and such, nothing to show
+	return nil
+}
+
+// String is the event as a text line:
+//
+//	[1234 ms] KEYDOWN windowid=1 state=pressed repeat=0 key=Q scancode=Q mod=LGUI
+func (e EventInfo) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "[%d ms] %s", e.Timestamp, e.Type)
+	for _, f := range e.Fields {
+		value := fmt.Sprint(f.Value)
+		if s, ok := f.Value.(string); ok && (s == "" || strings.ContainsAny(s, " \"=")) {
+			// Key names like "Left Shift" have spaces
+			value = strconv.Quote(s)
+		}
+		fmt.Fprintf(&b, " %s=%s", f.Name, value)
+	}
+	return b.String()
+}
+
+// MarshalJSON is the event as a JSON object, fields in order:
+//
+//	{"type":"KEYDOWN","category":"key","timestamp":1234,"windowid":1,...}
+func (e EventInfo) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	add := func(name string, value interface{}) error {
+		if b.Len() == 0 {
+			b.WriteByte('{')
+		} else {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		v, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(v)
+		return nil
+	}
+
+	add("type", e.Type)
+	add("category", e.Category)
+	add("timestamp", e.Timestamp)
+	for _, f := range e.Fields {
+		if err := add(f.Name, f.Value); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+var eventTypes = []struct {
+	typ            uint32
+	name, category string
+}{
+	{sdl.QUIT, "QUIT", "quit"},
+	{sdl.APP_TERMINATING, "APP_TERMINATING", "app"},
+	{sdl.APP_LOWMEMORY, "APP_LOWMEMORY", "app"},
+	{sdl.APP_WILLENTERBACKGROUND, "APP_WILLENTERBACKGROUND", "app"},
+	{sdl.APP_DIDENTERBACKGROUND, "APP_DIDENTERBACKGROUND", "app"},
+	{sdl.APP_WILLENTERFOREGROUND, "APP_WILLENTERFOREGROUND", "app"},
+	{sdl.APP_DIDENTERFOREGROUND, "APP_DIDENTERFOREGROUND", "app"},
+	{sdl.WINDOWEVENT, "WINDOWEVENT", "window"},
+	{sdl.SYSWMEVENT, "SYSWMEVENT", "window"},
+	{sdl.KEYDOWN, "KEYDOWN", "key"},
+	{sdl.KEYUP, "KEYUP", "key"},
+	{sdl.TEXTEDITING, "TEXTEDITING", "text"},
+	{sdl.TEXTINPUT, "TEXTINPUT", "text"},
+	{sdl.MOUSEMOTION, "MOUSEMOTION", "mouse"},
+	{sdl.MOUSEBUTTONDOWN, "MOUSEBUTTONDOWN", "mouse"},
+	{sdl.MOUSEBUTTONUP, "MOUSEBUTTONUP", "mouse"},
+	{sdl.MOUSEWHEEL, "MOUSEWHEEL", "mouse"},
+	{sdl.JOYAXISMOTION, "JOYAXISMOTION", "joystick"},
+	{sdl.JOYBALLMOTION, "JOYBALLMOTION", "joystick"},
+	{sdl.JOYHATMOTION, "JOYHATMOTION", "joystick"},
+	{sdl.JOYBUTTONDOWN, "JOYBUTTONDOWN", "joystick"},
+	{sdl.JOYBUTTONUP, "JOYBUTTONUP", "joystick"},
+	{sdl.JOYDEVICEADDED, "JOYDEVICEADDED", "joystick"},
+	{sdl.JOYDEVICEREMOVED, "JOYDEVICEREMOVED", "joystick"},
+	{sdl.CONTROLLERAXISMOTION, "CONTROLLERAXISMOTION", "controller"},
+	{sdl.CONTROLLERBUTTONDOWN, "CONTROLLERBUTTONDOWN", "controller"},
+	{sdl.CONTROLLERBUTTONUP, "CONTROLLERBUTTONUP", "controller"},
+	{sdl.CONTROLLERDEVICEADDED, "CONTROLLERDEVICEADDED", "controller"},
+	{sdl.CONTROLLERDEVICEREMOVED, "CONTROLLERDEVICEREMOVED", "controller"},
+	{sdl.CONTROLLERDEVICEREMAPPED, "CONTROLLERDEVICEREMAPPED", "controller"},
+	{sdl.FINGERDOWN, "FINGERDOWN", "touch"},
+	{sdl.FINGERUP, "FINGERUP", "touch"},
+	{sdl.FINGERMOTION, "FINGERMOTION", "touch"},
+	{sdl.DOLLARGESTURE, "DOLLARGESTURE", "gesture"},
+	{sdl.DOLLARRECORD, "DOLLARRECORD", "gesture"},
+	{sdl.MULTIGESTURE, "MULTIGESTURE", "gesture"},
+	{sdl.CLIPBOARDUPDATE, "CLIPBOARDUPDATE", "clipboard"},
+	{sdl.DROPFILE, "DROPFILE", "drop"},
+}
+
+func eventTypeName(typ uint32) (string, string) {
+	for _, t := range eventTypes {
+		if t.typ == typ {
+			return t.name, t.category
+		}
+	}
+	if typ >= sdl.USEREVENT && typ < sdl.LASTEVENT {
+		// Registered with sdl.RegisterEvents, or pushed by the game
+		return fmt.Sprintf("USEREVENT+%d", typ-sdl.USEREVENT), "user"
+	}
+	return fmt.Sprintf("0x%x", typ), "unknown"
+}
+
+func knownEventType(name string) bool {
+	if name == "USER" || name == "UNKNOWN" {
+		return true
+	}
+	for _, t := range eventTypes {
+		if t.name == name || strings.ToUpper(t.category) == name {
+			return true
+		}
+	}
+	return false
+}
+
+var windowEventNames = map[uint8]string{
+	sdl.WINDOWEVENT_NONE:         "NONE",
+	sdl.WINDOWEVENT_SHOWN:        "SHOWN",
+	sdl.WINDOWEVENT_HIDDEN:       "HIDDEN",
+	sdl.WINDOWEVENT_EXPOSED:      "EXPOSED",
+	sdl.WINDOWEVENT_MOVED:        "MOVED",
+	sdl.WINDOWEVENT_RESIZED:      "RESIZED",
+	sdl.WINDOWEVENT_SIZE_CHANGED: "SIZE_CHANGED",
+	sdl.WINDOWEVENT_MINIMIZED:    "MINIMIZED",
+	sdl.WINDOWEVENT_MAXIMIZED:    "MAXIMIZED",
+	sdl.WINDOWEVENT_RESTORED:     "RESTORED",
+	sdl.WINDOWEVENT_ENTER:        "ENTER",
+	sdl.WINDOWEVENT_LEAVE:        "LEAVE",
+	sdl.WINDOWEVENT_FOCUS_GAINED: "FOCUS_GAINED",
+	sdl.WINDOWEVENT_FOCUS_LOST:   "FOCUS_LOST",
+	sdl.WINDOWEVENT_CLOSE:        "CLOSE",
+}
+
+func windowEventName(event uint8) string {
+	if name, ok := windowEventNames[event]; ok {
+		return name
+	}
+	return strconv.Itoa(int(event))
+}
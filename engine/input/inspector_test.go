@@ -0,0 +1,209 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/veandco/go-sdl2/sdl"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// field finds a decoded field by name.
+func field(info EventInfo, name string) (interface{}, bool) {
+	for _, f := range info.Fields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+func TestDecodeEventKey(t *testing.T) {
+	info := DecodeEvent(&sdl.KeyDownEvent{
+		Type: sdl.KEYDOWN, Timestamp: 1234, WindowID: 1, State: sdl.PRESSED,
+		Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_Q, Sym: sdl.K_q, Mod: sdl.KMOD_LGUI | sdl.KMOD_CAPS},
+	})
+	if info.Type != "KEYDOWN" || info.Category != "key" || info.Timestamp != 1234 {
+		t.Errorf("got %s, %s at %d", info.Type, info.Category, info.Timestamp)
+	}
+	want := []EventField{
+		{"windowid", uint64(1)},
+		{"state", "pressed"},
+		{"repeat", uint64(0)},
+		{"key", "Q"},
+		{"scancode", "Q"},
+		{"mod", "LGUI|CAPS"},
+	}
+	if !reflect.DeepEqual(info.Fields, want) {
+		t.Errorf("fields %v, want %v", info.Fields, want)
+	}
+	if got, want := info.String(), "[1234 ms] KEYDOWN windowid=1 state=pressed repeat=0 key=Q scancode=Q mod=LGUI|CAPS"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	tests := []struct {
+		event         sdl.Event
+		typ, category string
+		name          string
+		value         interface{}
+	}{
+		{&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONUP, Button: sdl.BUTTON_RIGHT}, "MOUSEBUTTONUP", "mouse", "button", "Right"},
+		{&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Button: 9}, "MOUSEBUTTONDOWN", "mouse", "button", uint64(9)},
+		{&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, XRel: -2}, "MOUSEMOTION", "mouse", "xrel", int64(-2)},
+		{&sdl.ControllerAxisEvent{Type: sdl.CONTROLLERAXISMOTION, Axis: sdl.CONTROLLER_AXIS_LEFTY, Value: -300}, "CONTROLLERAXISMOTION", "controller", "axis", "lefty"},
+		{&sdl.ControllerAxisEvent{Type: sdl.CONTROLLERAXISMOTION, Value: -300}, "CONTROLLERAXISMOTION", "controller", "value", int64(-300)},
+		{&sdl.ControllerButtonEvent{Type: sdl.CONTROLLERBUTTONDOWN, Button: sdl.CONTROLLER_BUTTON_A, State: sdl.PRESSED}, "CONTROLLERBUTTONDOWN", "controller", "button", "a"},
+		{&sdl.WindowEvent{Type: sdl.WINDOWEVENT, Event: sdl.WINDOWEVENT_FOCUS_LOST}, "WINDOWEVENT", "window", "event", "FOCUS_LOST"},
+		{&sdl.TextInputEvent{Type: sdl.TEXTINPUT, Text: [32]byte{'h', 'i'}}, "TEXTINPUT", "text", "text", "hi"},
+		{&sdl.UserEvent{Type: sdl.USEREVENT + 2, Code: 7}, "USEREVENT+2", "user", "code", int64(7)},
+		{&sdl.QuitEvent{Type: sdl.QUIT}, "QUIT", "quit", "", nil},
+	}
+	for _, test := range tests {
+		info := DecodeEvent(test.event)
+		if info.Type != test.typ || info.Category != test.category {
+			t.Errorf("%s: decoded as %s, %s, want %s", test.typ, info.Type, info.Category, test.category)
+		}
+		if test.name == "" {
+			continue
+		}
+		if value, ok := field(info, test.name); !ok || value != test.value {
+			t.Errorf("%s: %s=%#v, want %#v", test.typ, test.name, value, test.value)
+		}
+	}
+
+	// The user event's C pointers are left out
+	if _, ok := field(DecodeEvent(&sdl.UserEvent{Type: sdl.USEREVENT}), "data1"); ok {
+		t.Error("decoded a pointer")
+	}
+	if info := DecodeEvent(&sdl.QuitEvent{Type: 0x1234}); info.Type != "0x1234" || info.Category != "unknown" {
+		t.Errorf("unknown event decoded as %s, %s", info.Type, info.Category)
+	}
+}
+
+func TestEventInfoMarshalJSON(t *testing.T) {
+	info := DecodeEvent(&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Timestamp: 5, Button: sdl.BUTTON_LEFT, State: sdl.PRESSED, X: 4, Y: -1})
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys in order: the header, then the fields as decoded
+	want := []string{"type", "category", "timestamp"}
+	for _, f := range info.Fields {
+		want = append(want, f.Name)
+	}
+	var keys []string
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.Token()
+	for d.More() {
+		key, err := d.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key.(string))
+		var skip interface{}
+		if err := d.Decode(&skip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys %v, want %v", keys, want)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["type"] != "MOUSEBUTTONDOWN" || m["category"] != "mouse" || m["timestamp"] != 5.0 || m["button"] != "Left" || m["y"] != -1.0 {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestInspectorFilter(t *testing.T) {
+	events := []sdl.Event{
+		&sdl.KeyDownEvent{Type: sdl.KEYDOWN},
+		&sdl.KeyUpEvent{Type: sdl.KEYUP},
+		&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION},
+		&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN},
+		&sdl.UserEvent{Type: sdl.USEREVENT + 1},
+		&sdl.QuitEvent{Type: sdl.QUIT},
+	}
+	tests := []struct {
+		filter  []string
+		printed []string
+	}{
+		{nil, []string{"KEYDOWN", "KEYUP", "MOUSEMOTION", "MOUSEBUTTONDOWN", "USEREVENT+1", "QUIT"}},
+		{[]string{"key"}, []string{"KEYDOWN", "KEYUP"}},
+		{[]string{"Mouse", "quit"}, []string{"MOUSEMOTION", "MOUSEBUTTONDOWN", "QUIT"}},
+		{[]string{"keyup", " MOUSEMOTION "}, []string{"KEYUP", "MOUSEMOTION"}},
+		{[]string{"user"}, []string{"USEREVENT+1"}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		in := NewInspector(&out)
+		if err := in.Filter(test.filter...); err != nil {
+			t.Errorf("%v: %v", test.filter, err)
+			continue
+		}
+		for _, e := range events {
+			in.Print(e)
+		}
+		var printed []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			printed = append(printed, strings.Fields(line)[2])
+		}
+		if !reflect.DeepEqual(printed, test.printed) {
+			t.Errorf("%v: printed %v, want %v", test.filter, printed, test.printed)
+		}
+	}
+
+	in := NewInspector(&bytes.Buffer{})
+	in.Filter("key")
+	if err := in.Filter("key", "nope"); err == nil {
+		t.Error("no error for an unknown type")
+	}
+	// A bad filter leaves the old one
+	var out bytes.Buffer
+	in.Out = &out
+	in.Print(&sdl.QuitEvent{Type: sdl.QUIT})
+	if out.Len() != 0 {
+		t.Errorf("printed %q", out.String())
+	}
+}
+
+func TestInspectorToggle(t *testing.T) {
+	var out bytes.Buffer
+	in := NewInspector(&out)
+	quit := &sdl.QuitEvent{Type: sdl.QUIT}
+	f12 := sdl.Keysym{Scancode: sdl.SCANCODE_F12, Mod: sdl.KMOD_NUM}
+
+	if in.HandleEvent(quit) || out.Len() != 0 {
+		t.Fatal("printed while off")
+	}
+	if !in.HandleEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Keysym: f12}) || !in.Enabled {
+		t.Fatal("F12 didn't turn it on")
+	}
+	// Repeats and the key up are the toggle's too, and don't flip it back
+	if !in.HandleEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Repeat: 1, Keysym: f12}) || !in.Enabled {
+		t.Error("repeat")
+	}
+	if !in.HandleEvent(&sdl.KeyUpEvent{Type: sdl.KEYUP, Keysym: f12}) || !in.Enabled {
+		t.Error("key up")
+	}
+	if out.Len() != 0 {
+		t.Errorf("printed the toggle: %q", out.String())
+	}
+
+	if in.HandleEvent(quit) || !strings.Contains(out.String(), "QUIT") {
+		t.Error("not printed while on")
+	}
+	if in.HandleEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_F12, Mod: sdl.KMOD_LSHIFT}}) {
+		t.Error("Shift+F12 taken for the toggle")
+	}
+	if !in.HandleEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_F12}}) || in.Enabled {
+		t.Error("F12 didn't turn it off")
+	}
+}
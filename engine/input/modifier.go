@@ -0,0 +1,57 @@
+package input
+
+import (
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"strings"
+)
+
+// ModifierKey is a Keysym.Mod mask. It prints every flag set, e.g.
+// "LSHIFT|LCTRL", or "NONE".
+type ModifierKey uint16
+
+// Lock modifiers stay on after the key is let go, they're never part of a
+// chord
+const lockModifiers = sdl.KMOD_NUM | sdl.KMOD_CAPS | sdl.KMOD_MODE
+
+var modifierNames = []struct {
+	mask uint16
+	name string
+}{
+	{sdl.KMOD_LSHIFT, "LSHIFT"},
+	{sdl.KMOD_RSHIFT, "RSHIFT"},
+	{sdl.KMOD_LCTRL, "LCTRL"},
+	{sdl.KMOD_RCTRL, "RCTRL"},
+	{sdl.KMOD_LALT, "LALT"},
+	{sdl.KMOD_RALT, "RALT"},
+	{sdl.KMOD_LGUI, "LGUI"},
+	{sdl.KMOD_RGUI, "RGUI"},
+	{sdl.KMOD_NUM, "NUM"},
+	{sdl.KMOD_CAPS, "CAPS"},
+	{sdl.KMOD_MODE, "MODE"},
+	{sdl.KMOD_RESERVED, "RESERVED"},
+}
+
+func (m ModifierKey) String() string {
+	if m == sdl.KMOD_NONE {
+		return "NONE"
+	}
+
+	var names []string
+	rest := uint16(m)
+	for _, n := range modifierNames {
+		if rest&n.mask != 0 {
+			names = append(names, n.name)
+			rest &^= n.mask
+		}
+	}
+	if rest != 0 {
+		names = append(names, fmt.Sprintf("0x%04x", rest))
+	}
+	return strings.Join(names, "|")
+}
+
+// WithoutLocks is m without Num Lock, Caps Lock and AltGr (Mode).
+func (m ModifierKey) WithoutLocks() ModifierKey {
+	return m &^ lockModifiers
+}
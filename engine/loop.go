@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"github.com/paydro/gamedev/engine/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Game is anything Loop.Run can drive.
+type Game interface {
+	// HandleEvent is called for every pending SDL event before updating.
+	// Return false to stop the loop.
+	HandleEvent(e sdl.Event) bool
+
+	// Update advances the game by dt seconds. In a fixed step loop dt is
+	// always Loop.Step().
+	Update(dt float64)
+
+	// Draw renders a frame, Present() is done by the loop. alpha (0 to 1) is
+	// how far the frame is between the previous and the current update, use
+	// it to interpolate positions. An error stops the loop.
+	Draw(r *sdl.Renderer, alpha float64) error
+}
+
+// Loop is a fixed timestep game loop. Updates run UpdateRate times a second no
+// matter how fast frames are rendered, so movement no longer depends on
+// machine load. Rendering is capped at the Clock's FPS.
+//
+// See http://gafferongames.com/game-physics/fix-your-timestep/
+type Loop struct {
+	// Fixed updates a second, independent of the render FPS.
+	UpdateRate int
+
+	// Most updates to run for one rendered frame. When a frame takes too
+	// long the left over time is dropped instead of trying to catch up
+	// forever (the spiral of death).
+	MaxUpdates int
+
+	// Stop Run() after this many rendered frames. 0 runs until the game
+	// quits. Handy for smoke testing a demo in a headless window.
+	Frames int
+
+	// Paces rendering. Set Clock.Time to run the loop on a fake clock.
+	Clock *Clock
+
+	// The loop's own time, moved on by every frame's elapsed ms (the
+	// recorded ones when replaying). Give it to Sprites so animations play
+	// back the way they were recorded:
+	//
+	//	yoshi.Time = loop.Time
+	Time *ManualTime
+
+	// Saves every frame's time and input events when set.
+	Recorder *Recorder
+
+	// Plays a recording back instead of the clock's time and SDL's input,
+	// at the UpdateRate and MaxUpdates it was recorded with. Run stops at
+	// its end, or with a DesyncError when a Checksummer game doesn't end a
+	// frame like it did when recording.
+	Replay *Replay
+
+	// Prints the events Run handles when set, toggled with F12. Set
+	// Inspector.Enabled to start with it on. nil by default, F12 is the
+	// game's.
+	Inspector *input.Inspector
+
+	accumulator float64 // ms not yet consumed by updates
+}
+
+func NewLoop(updateRate, fps int) *Loop {
+	return &Loop{
+		UpdateRate: updateRate,
+		MaxUpdates: 5,
+		Clock:      NewClock(fps),
+		Time:       NewManualTime(),
+	}
+}
+
+// Step is the fixed dt, in seconds, passed to every update.
+func (l *Loop) Step() float64 {
+	return 1.0 / float64(l.UpdateRate)
+}
+
+// Tick waits for the next frame and returns how many fixed updates to run
+// before rendering it, plus the interpolation alpha to render with.
+func (l *Loop) Tick() (int, float64) {
+	return l.advance(l.Clock.Tick())
+}
+
+// advance adds elapsed ms to the accumulator and takes the updates out.
+func (l *Loop) advance(elapsed int) (int, float64) {
+	stepMs := l.Step() * 1000.0
+
+	if l.Time != nil {
+		l.Time.Advance(uint32(elapsed))
+	}
+	l.accumulator += float64(elapsed)
+
+	maxMs := stepMs * float64(l.MaxUpdates)
+	if l.accumulator > maxMs {
+		l.accumulator = maxMs
+	}
+
+	updates := 0
+	for l.accumulator >= stepMs {
+		l.accumulator -= stepMs
+		updates += 1
+	}
+
+	return updates, l.accumulator / stepMs
+}
+
+// Run drives g on w until HandleEvent returns false, or for l.Frames frames
+// when set. It returns the first error from rendering, recording or replaying.
+func (l *Loop) Run(w *Window, g Game) error {
+	if l.Replay != nil {
+		l.UpdateRate = l.Replay.UpdateRate
+		if l.Replay.MaxUpdates > 0 {
+			l.MaxUpdates = l.Replay.MaxUpdates
+		}
+		// Recorded controllers aren't plugged in here
+		input.SetReplaying(true)
+		defer input.SetReplaying(false)
+	}
+
+	var events []sdl.Event
+	running := true
+	for frame := 0; running && (l.Frames == 0 || frame < l.Frames); frame++ {
+		// Still paced by the clock when replaying, to play at normal speed
+		elapsed := l.Clock.Tick()
+		events = events[:0]
+
+		var replayed replayFrame
+		if l.Replay != nil {
+			var ok bool
+			if replayed, ok = l.Replay.nextFrame(); !ok {
+				break
+			}
+			elapsed = replayed.elapsed
+			events = append(events, replayed.events...)
+
+			// Live input is ignored, but closing the window and the
+			// inspector still work
+			for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+				if _, ok := event.(*sdl.QuitEvent); ok {
+					running = false
+				}
+				if l.Inspector != nil {
+					l.Inspector.Toggled(event)
+				}
+			}
+		} else {
+			for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+				// The inspector's toggle key isn't game input, it's left
+				// out of recordings too
+				if l.Inspector != nil && l.Inspector.Toggled(event) {
+					continue
+				}
+				events = append(events, event)
+			}
+		}
+
+		updates, alpha := l.advance(elapsed)
+
+		for _, event := range events {
+			if l.Inspector != nil && l.Inspector.HandleEvent(event) {
+				continue
+			}
+			if !g.HandleEvent(event) {
+				running = false
+			}
+		}
+
+		for i := 0; i < updates; i++ {
+			g.Update(l.Step())
+		}
+
+		// The state only changes with updates, no need to check it otherwise
+		var checksum uint64
+		summer, hasChecksum := g.(Checksummer)
+		hasChecksum = hasChecksum && updates > 0
+		if hasChecksum {
+			checksum = summer.Checksum()
+		}
+		if l.Recorder != nil {
+			if err := l.Recorder.frame(elapsed, events, checksum, hasChecksum); err != nil {
+				return err
+			}
+		}
+		if l.Replay != nil && hasChecksum && replayed.hasChecksum && checksum != replayed.checksum {
+			return &DesyncError{Frame: l.Replay.Frame() - 1, Want: replayed.checksum, Got: checksum}
+		}
+
+		if w.Assets != nil {
+			w.Assets.Reload()
+		}
+
+		if err := g.Draw(w.Renderer(), alpha); err != nil {
+			return err
+		}
+		if err := Present(w.Renderer()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
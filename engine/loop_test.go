@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLoopAdvance(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed []int
+		updates int
+		alpha   float64
+	}{
+		{"nothing yet", []int{0}, 0, 0},
+		{"less than a step", []int{10}, 0, 0.5},
+		{"one step", []int{20}, 1, 0},
+		{"step and a half", []int{30}, 1, 0.5},
+		{"left over adds up", []int{30, 10}, 1, 0},
+		{"several steps", []int{65}, 3, 0.25},
+		{"clamped to MaxUpdates", []int{1000}, 5, 0},
+	}
+
+	for _, test := range tests {
+		l := NewLoop(50, 50)
+		var updates int
+		var alpha float64
+		for _, ms := range test.elapsed {
+			updates, alpha = l.advance(ms)
+		}
+		if updates != test.updates || math.Abs(alpha-test.alpha) > 1e-9 {
+			t.Errorf("%s: got %d updates, alpha %v, want %d, %v",
+				test.name, updates, alpha, test.updates, test.alpha)
+		}
+	}
+}
+
+func TestLoopTickManualTime(t *testing.T) {
+	fake := NewManualTime()
+	l := NewLoop(50, 50)
+	l.Clock.Time = fake
+
+	if updates, alpha := l.Tick(); updates != 0 || alpha != 0 {
+		t.Fatalf("first tick: got %d updates, alpha %v, want 0, 0", updates, alpha)
+	}
+
+	// The clock waits out the rest of the 20 ms frame, one update a frame
+	for i := 0; i < 10; i++ {
+		fake.Advance(5)
+		if updates, alpha := l.Tick(); updates != 1 || alpha != 0 {
+			t.Fatalf("frame %d: got %d updates, alpha %v, want 1, 0", i, updates, alpha)
+		}
+	}
+	if fake.Now != 200 {
+		t.Errorf("clock at %d ms after 10 frames, want 200", fake.Now)
+	}
+
+	// A slow frame runs more updates and interpolates the rest
+	fake.Advance(50)
+	if updates, alpha := l.Tick(); updates != 2 || math.Abs(alpha-0.5) > 1e-9 {
+		t.Errorf("slow frame: got %d updates, alpha %v, want 2, 0.5", updates, alpha)
+	}
+
+	// A stall doesn't try to catch up all of it
+	fake.Advance(10000)
+	if updates, _ := l.Tick(); updates != l.MaxUpdates {
+		t.Errorf("stall: got %d updates, want MaxUpdates (%d)", updates, l.MaxUpdates)
+	}
+}
+
+func TestClockAverage(t *testing.T) {
+	fake := NewManualTime()
+	c := NewClock(60)
+	c.Time = fake
+
+	c.Tick()
+	total := 0
+	for i := 0; i < 60; i++ {
+		total += c.Tick()
+	}
+	if total != 1000 {
+		t.Errorf("60 frames at 60 FPS took %d ms, want 1000", total)
+	}
+}
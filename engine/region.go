@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TextureRegion is part of a texture, like an image packed into an Atlas.
+type TextureRegion struct {
+	Texture *sdl.Texture
+	Rect    sdl.Rect
+}
+
+// RenderRegion is RenderTexture for part of a texture.
+func RenderRegion(reg *TextureRegion, r *sdl.Renderer, x, y, w, h int) error {
+	rect := sdl.Rect{
+		X: int32(x),
+		Y: int32(y),
+		W: int32(w),
+		H: int32(h),
+	}
+	return Copy(r, reg.Texture, &reg.Rect, &rect)
+}
+
+// RenderOriginalRegion draws the region at x, y using its own size.
+func RenderOriginalRegion(reg *TextureRegion, r *sdl.Renderer, x, y int) error {
+	return RenderRegion(reg, r, x, y, int(reg.Rect.W), int(reg.Rect.H))
+}
+
+// SubmitRegion is RenderRegion for a RenderQueue.
+func SubmitRegion(reg *TextureRegion, q *RenderQueue, x, y, w, h, layer int, z float64) {
+	q.Submit(DrawCommand{
+		Texture: reg.Texture,
+		Src:     reg.Rect,
+		Dst:     sdl.Rect{X: int32(x), Y: int32(y), W: int32(w), H: int32(h)},
+		Layer:   layer,
+		Z:       z,
+	})
+}
+
+// SpriteSheet cuts the region into a grid of cells, for sprite sheets that
+// were packed into an atlas.
+func (reg *TextureRegion) SpriteSheet(cellWidth, cellHeight int) (*SpriteSheet, error) {
+	if cellWidth < 1 || cellHeight < 1 {
+		return nil, errors.New(fmt.Sprintf("Bad sprite sheet cell size %dx%d", cellWidth, cellHeight))
+	}
+	columns := int(reg.Rect.W) / cellWidth
+	rows := int(reg.Rect.H) / cellHeight
+
+	grid, err := NewSpriteSheetGrid(reg.Texture, cellWidth, cellHeight, columns, rows)
+	if err != nil {
+		return nil, err
+	}
+	grid.Cells = make([]sdl.Rect, 0, columns*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < columns; col++ {
+			cell := grid.RectAt(row, col)
+			cell.X += reg.Rect.X
+			cell.Y += reg.Rect.Y
+			grid.Cells = append(grid.Cells, cell)
+		}
+	}
+	return grid, nil
+}
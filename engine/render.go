@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// go-sdl2 returns SDL's ints and nil pointers as is. The functions in this
+// file wrap the render calls the engine uses so that failures come back as an
+// error and can be propagated instead of silently drawing nothing.
+
+// SDLError is a failed SDL call.
+type SDLError struct {
+	// The SDL function that failed, e.g. "SDL_RenderCopy"
+	Op string
+
+	// SDL_GetError() right after the failure
+	Msg string
+}
+
+func (e *SDLError) Error() string {
+	return e.Op + ": " + e.Msg
+}
+
+// sdlError builds an SDLError for op from SDL's last error message.
+func sdlError(op string) error {
+	msg := "unknown error"
+	if err := sdl.GetError(); err != nil && err.Error() != "" {
+		msg = err.Error()
+	}
+	return &SDLError{Op: op, Msg: msg}
+}
+
+// Copy draws the src part of t into dst. nil rects mean the whole texture or
+// the whole render target. dst is in world coordinates when a Camera is set
+// on r.
+func Copy(r *sdl.Renderer, t *sdl.Texture, src, dst *sdl.Rect) error {
+	dst = toScreen(r, dst)
+	if r.Copy(t, src, dst) < 0 {
+		return sdlError("SDL_RenderCopy")
+	}
+	return nil
+}
+
+// CopyEx is Copy with rotation (in degrees, around center or the middle of
+// dst when nil) and flipping.
+func CopyEx(r *sdl.Renderer, t *sdl.Texture, src, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) error {
+	dst = toScreen(r, dst)
+	if r.CopyEx(t, src, dst, angle, center, flip) < 0 {
+		return sdlError("SDL_RenderCopyEx")
+	}
+	return nil
+}
+
+// toScreen moves dst through r's camera, if it has one.
+func toScreen(r *sdl.Renderer, dst *sdl.Rect) *sdl.Rect {
+	c := CameraFor(r)
+	if c == nil || dst == nil {
+		return dst
+	}
+	screen := c.WorldToScreenRect(*dst)
+	return &screen
+}
+
+// Clear fills the render target with the current draw color.
+func Clear(r *sdl.Renderer) error {
+	if r.Clear() < 0 {
+		return sdlError("SDL_RenderClear")
+	}
+	return nil
+}
+
+// SetDrawColor sets the color used by Clear and the primitive drawing calls.
+func SetDrawColor(r *sdl.Renderer, red, green, blue, alpha uint8) error {
+	if r.SetDrawColor(red, green, blue, alpha) < 0 {
+		return sdlError("SDL_SetRenderDrawColor")
+	}
+	return nil
+}
+
+// Present shows everything drawn since the last Present. SDL_RenderPresent
+// can't report failures, this is here so render code goes through the same
+// layer for every call.
+func Present(r *sdl.Renderer) error {
+	r.Present()
+	return nil
+}
+
+// QueryTexture returns the original width / height of a texture.
+func QueryTexture(t *sdl.Texture) (int, int, error) {
+	var w, h int
+	if sdl.QueryTexture(t, nil, nil, &w, &h) < 0 {
+		return 0, 0, sdlError("SDL_QueryTexture")
+	}
+	return w, h, nil
+}
@@ -0,0 +1,438 @@
+package engine
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+)
+
+// Recordings are a gzipped stream of frames, after a header with the loop's
+// UpdateRate and MaxUpdates. Each frame is the ms the clock gave the loop,
+// the input events handled during it and, for games that have one, a
+// checksum of the game state after its updates. Numbers are varints, so an
+// idle frame is a handful of bytes.
+const (
+	replayMagic   = "GDREPLAY"
+	replayVersion = 2
+
+	frameHasChecksum = 1
+)
+
+// Checksummer is a Game that can sum up its state, e.g. the positions of
+// its entities. Replays check it frame by frame to catch where playback
+// stops matching the recording.
+type Checksummer interface {
+	Checksum() uint64
+}
+
+// HashValues is a quick checksum of some numbers, for Checksum():
+//
+//	return engine.HashValues(yoshi.X, yoshi.Y, yoshi.VX, yoshi.VY)
+func HashValues(values ...float64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// DesyncError is returned by Loop.Run when a replayed frame doesn't end up
+// with the recorded checksum: the game did something the recording doesn't
+// capture (random numbers, reading the keyboard state directly ...).
+type DesyncError struct {
+	Frame     int
+	Want, Got uint64
+}
+
+func (e *DesyncError) Error() string {
+	return fmt.Sprintf("replay desync at frame %d: checksum %016x, recorded %016x", e.Frame, e.Got, e.Want)
+}
+
+// Recorder saves the frames of a Loop to a file. Set it as Loop.Recorder
+// and Close it when the loop is done.
+type Recorder struct {
+	file *os.File
+	gz   *gzip.Writer
+	w    *bufio.Writer
+	buf  []byte
+}
+
+func NewRecorder(path string, l *Loop) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(f)
+	r := &Recorder{file: f, gz: gz, w: bufio.NewWriter(gz)}
+
+	r.buf = append(r.buf, replayMagic...)
+	r.buf = binary.AppendUvarint(r.buf, replayVersion)
+	r.buf = binary.AppendUvarint(r.buf, uint64(l.UpdateRate))
+	r.buf = binary.AppendUvarint(r.buf, uint64(l.MaxUpdates))
+	if _, err := r.w.Write(r.buf); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// frame records one frame of the loop.
+func (r *Recorder) frame(elapsed int, events []sdl.Event, checksum uint64, hasChecksum bool) error {
+	b := r.buf[:0]
+	b = binary.AppendUvarint(b, uint64(elapsed))
+
+	var flags uint64
+	if hasChecksum {
+		flags |= frameHasChecksum
+	}
+	b = binary.AppendUvarint(b, flags)
+	if hasChecksum {
+		b = binary.LittleEndian.AppendUint64(b, checksum)
+	}
+
+	count := 0
+	for _, e := range events {
+		if _, ok := appendEvent(nil, e); ok {
+			count++
+		}
+	}
+	b = binary.AppendUvarint(b, uint64(count))
+	for _, e := range events {
+		b, _ = appendEvent(b, e)
+	}
+
+	r.buf = b
+	_, err := r.w.Write(b)
+	return err
+}
+
+func (r *Recorder) Close() error {
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	if err := r.gz.Close(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Replay plays back a recording. Set it as Loop.Replay: the loop then uses
+// the recorded frame times and events instead of the clock's and SDL's, and
+// stops at the end of the recording. The window can still be closed.
+type Replay struct {
+	// Loop settings the recording was made with. MaxUpdates is 0 in
+	// version 1 recordings, which didn't save it.
+	UpdateRate int
+	MaxUpdates int
+
+	frames []replayFrame
+	next   int
+}
+
+type replayFrame struct {
+	elapsed     int
+	events      []sdl.Event
+	checksum    uint64
+	hasChecksum bool
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(gz)
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != replayMagic {
+		return nil, errors.New(fmt.Sprintf("%s is not a replay", path))
+	}
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 || version > replayVersion {
+		return nil, errors.New(fmt.Sprintf("%s: replay version %d, only up to %d is supported", path, version, replayVersion))
+	}
+	rate, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	var maxUpdates uint64
+	if version >= 2 {
+		if maxUpdates, err = binary.ReadUvarint(r); err != nil {
+			return nil, err
+		}
+	}
+
+	replay := &Replay{UpdateRate: int(rate), MaxUpdates: int(maxUpdates)}
+	for {
+		frame, err := readFrame(r)
+		if err == io.EOF {
+			return replay, nil
+		}
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("%s: frame %d: %v", path, len(replay.frames), err))
+		}
+		replay.frames = append(replay.frames, frame)
+	}
+}
+
+func readFrame(r *bufio.Reader) (replayFrame, error) {
+	var f replayFrame
+	elapsed, err := binary.ReadUvarint(r)
+	if err != nil {
+		// A clean end of file only between frames
+		return f, err
+	}
+	f.elapsed = int(elapsed)
+
+	flags, err := binary.ReadUvarint(r)
+	if err != nil {
+		return f, unexpectedEOF(err)
+	}
+	if flags&frameHasChecksum != 0 {
+		var sum [8]byte
+		if _, err := io.ReadFull(r, sum[:]); err != nil {
+			return f, unexpectedEOF(err)
+		}
+		f.checksum = binary.LittleEndian.Uint64(sum[:])
+		f.hasChecksum = true
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return f, unexpectedEOF(err)
+	}
+	for i := uint64(0); i < count; i++ {
+		e, err := readEvent(r)
+		if err != nil {
+			return f, unexpectedEOF(err)
+		}
+		f.events = append(f.events, e)
+	}
+	return f, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Len is the number of frames recorded.
+func (p *Replay) Len() int {
+	return len(p.frames)
+}
+
+// Frame is the index of the next frame to play.
+func (p *Replay) Frame() int {
+	return p.next
+}
+
+// Done is true once every frame was played.
+func (p *Replay) Done() bool {
+	return p.next >= len(p.frames)
+}
+
+// Rewind goes back to the first frame.
+func (p *Replay) Rewind() {
+	p.next = 0
+}
+
+func (p *Replay) nextFrame() (replayFrame, bool) {
+	if p.Done() {
+		return replayFrame{}, false
+	}
+	f := p.frames[p.next]
+	p.next++
+	return f, true
+}
+
+// Input events are recorded as their SDL type followed by their fields.
+// Timestamps and window ids aren't kept.
+
+func appendEvent(b []byte, event sdl.Event) ([]byte, bool) {
+	switch t := event.(type) {
+	case *sdl.QuitEvent:
+		b = binary.AppendUvarint(b, sdl.QUIT)
+	case *sdl.KeyDownEvent:
+		b = binary.AppendUvarint(b, sdl.KEYDOWN)
+		b = appendKey(b, t.State, t.Repeat, t.Keysym)
+	case *sdl.KeyUpEvent:
+		b = binary.AppendUvarint(b, sdl.KEYUP)
+		b = appendKey(b, t.State, t.Repeat, t.Keysym)
+	case *sdl.MouseMotionEvent:
+		b = binary.AppendUvarint(b, sdl.MOUSEMOTION)
+		b = binary.AppendUvarint(b, uint64(t.Which))
+		b = binary.AppendUvarint(b, uint64(t.State))
+		b = binary.AppendVarint(b, int64(t.X))
+		b = binary.AppendVarint(b, int64(t.Y))
+		b = binary.AppendVarint(b, int64(t.XRel))
+		b = binary.AppendVarint(b, int64(t.YRel))
+	case *sdl.MouseButtonEvent:
+		b = binary.AppendUvarint(b, uint64(t.Type))
+		b = binary.AppendUvarint(b, uint64(t.Which))
+		b = append(b, t.Button, t.State)
+		b = binary.AppendVarint(b, int64(t.X))
+		b = binary.AppendVarint(b, int64(t.Y))
+	case *sdl.MouseWheelEvent:
+		b = binary.AppendUvarint(b, sdl.MOUSEWHEEL)
+		b = binary.AppendUvarint(b, uint64(t.Which))
+		b = binary.AppendVarint(b, int64(t.X))
+		b = binary.AppendVarint(b, int64(t.Y))
+	case *sdl.ControllerAxisEvent:
+		b = binary.AppendUvarint(b, sdl.CONTROLLERAXISMOTION)
+		b = binary.AppendVarint(b, int64(t.Which))
+		b = append(b, t.Axis)
+		b = binary.AppendVarint(b, int64(t.Value))
+	case *sdl.ControllerButtonEvent:
+		b = binary.AppendUvarint(b, uint64(t.Type))
+		b = binary.AppendVarint(b, int64(t.Which))
+		b = append(b, t.Button, t.State)
+	case *sdl.ControllerDeviceEvent:
+		b = binary.AppendUvarint(b, uint64(t.Type))
+		b = binary.AppendVarint(b, int64(t.Which))
+	default:
+		return b, false
+	}
+	return b, true
+}
+
+func appendKey(b []byte, state, repeat uint8, k sdl.Keysym) []byte {
+	b = append(b, state, repeat)
+	b = binary.AppendUvarint(b, uint64(k.Scancode))
+	b = binary.AppendVarint(b, int64(k.Sym))
+	return binary.AppendUvarint(b, uint64(k.Mod))
+}
+
+func readEvent(r *bufio.Reader) (sdl.Event, error) {
+	typ, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything read goes through d, the first error sticks
+	d := &eventDecoder{r: r}
+	var event sdl.Event
+	switch typ {
+	case sdl.QUIT:
+		event = &sdl.QuitEvent{Type: sdl.QUIT}
+	case sdl.KEYDOWN:
+		e := &sdl.KeyDownEvent{Type: sdl.KEYDOWN}
+		e.State, e.Repeat, e.Keysym = d.key()
+		event = e
+	case sdl.KEYUP:
+		e := &sdl.KeyUpEvent{Type: sdl.KEYUP}
+		e.State, e.Repeat, e.Keysym = d.key()
+		event = e
+	case sdl.MOUSEMOTION:
+		event = &sdl.MouseMotionEvent{
+			Type:  sdl.MOUSEMOTION,
+			Which: uint32(d.uvarint()),
+			State: uint32(d.uvarint()),
+			X:     int32(d.varint()),
+			Y:     int32(d.varint()),
+			XRel:  int32(d.varint()),
+			YRel:  int32(d.varint()),
+		}
+	case sdl.MOUSEBUTTONDOWN, sdl.MOUSEBUTTONUP:
+		event = &sdl.MouseButtonEvent{
+			Type:   uint32(typ),
+			Which:  uint32(d.uvarint()),
+			Button: d.byte(),
+			State:  d.byte(),
+			X:      int32(d.varint()),
+			Y:      int32(d.varint()),
+		}
+	case sdl.MOUSEWHEEL:
+		event = &sdl.MouseWheelEvent{
+			Type:  sdl.MOUSEWHEEL,
+			Which: uint32(d.uvarint()),
+			X:     int32(d.varint()),
+			Y:     int32(d.varint()),
+		}
+	case sdl.CONTROLLERAXISMOTION:
+		event = &sdl.ControllerAxisEvent{
+			Type:  sdl.CONTROLLERAXISMOTION,
+			Which: sdl.JoystickID(d.varint()),
+			Axis:  d.byte(),
+			Value: int16(d.varint()),
+		}
+	case sdl.CONTROLLERBUTTONDOWN, sdl.CONTROLLERBUTTONUP:
+		event = &sdl.ControllerButtonEvent{
+			Type:   uint32(typ),
+			Which:  sdl.JoystickID(d.varint()),
+			Button: d.byte(),
+			State:  d.byte(),
+		}
+	case sdl.CONTROLLERDEVICEADDED, sdl.CONTROLLERDEVICEREMOVED, sdl.CONTROLLERDEVICEREMAPPED:
+		event = &sdl.ControllerDeviceEvent{
+			Type:  uint32(typ),
+			Which: sdl.JoystickID(d.varint()),
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown event type 0x%x", typ))
+	}
+	return event, d.err
+}
+
+type eventDecoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *eventDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var v uint64
+	v, d.err = binary.ReadUvarint(d.r)
+	return v
+}
+
+func (d *eventDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	var v int64
+	v, d.err = binary.ReadVarint(d.r)
+	return v
+}
+
+func (d *eventDecoder) byte() uint8 {
+	if d.err != nil {
+		return 0
+	}
+	var v byte
+	v, d.err = d.r.ReadByte()
+	return v
+}
+
+func (d *eventDecoder) key() (uint8, uint8, sdl.Keysym) {
+	state, repeat := d.byte(), d.byte()
+	k := sdl.Keysym{
+		Scancode: uint32(d.uvarint()),
+		Sym:      sdl.Keycode(d.varint()),
+		Mod:      uint16(d.uvarint()),
+	}
+	return state, repeat, k
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"github.com/paydro/gamedev/engine/input"
+	"github.com/veandco/go-sdl2/sdl"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Every event type recordings keep, with fields that need every bit of
+// their varints.
+var replayEvents = []sdl.Event{
+	&sdl.QuitEvent{Type: sdl.QUIT},
+	&sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Repeat: 1, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_A, Sym: sdl.K_a, Mod: sdl.KMOD_LCTRL | sdl.KMOD_CAPS}},
+	&sdl.KeyUpEvent{Type: sdl.KEYUP, State: sdl.RELEASED, Keysym: sdl.Keysym{Scancode: 0x1ff, Sym: -5, Mod: 0xffff}},
+	&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, Which: 2, State: 5, X: 10, Y: -3, XRel: -1, YRel: 70000},
+	&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Which: 1, Button: sdl.BUTTON_LEFT, State: sdl.PRESSED, X: 5, Y: 6},
+	&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONUP, Button: sdl.BUTTON_X2, State: sdl.RELEASED, X: -5, Y: 0},
+	&sdl.MouseWheelEvent{Type: sdl.MOUSEWHEEL, Which: 1, X: -1, Y: 3},
+	&sdl.ControllerAxisEvent{Type: sdl.CONTROLLERAXISMOTION, Which: 3, Axis: sdl.CONTROLLER_AXIS_LEFTY, Value: -32768},
+	&sdl.ControllerAxisEvent{Type: sdl.CONTROLLERAXISMOTION, Which: -1, Axis: sdl.CONTROLLER_AXIS_TRIGGERRIGHT, Value: 32767},
+	&sdl.ControllerButtonEvent{Type: sdl.CONTROLLERBUTTONDOWN, Which: 3, Button: sdl.CONTROLLER_BUTTON_A, State: sdl.PRESSED},
+	&sdl.ControllerButtonEvent{Type: sdl.CONTROLLERBUTTONUP, Which: 3, Button: sdl.CONTROLLER_BUTTON_DPAD_LEFT, State: sdl.RELEASED},
+	&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEADDED, Which: 0},
+	&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEREMOVED, Which: 3},
+	&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEREMAPPED, Which: 4},
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.rec")
+	l := NewLoop(50, 60)
+	l.MaxUpdates = 3
+	r, err := NewRecorder(path, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Window events aren't input, they're left out
+	events := append([]sdl.Event{&sdl.WindowEvent{Type: sdl.WINDOWEVENT}}, replayEvents...)
+	if err := r.frame(16, events, 0xdeadbeefcafe, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.frame(1000, nil, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UpdateRate != 50 || p.MaxUpdates != 3 || p.Len() != 2 {
+		t.Fatalf("got rate %d, max %d, %d frames, want 50, 3, 2", p.UpdateRate, p.MaxUpdates, p.Len())
+	}
+
+	f, _ := p.nextFrame()
+	if f.elapsed != 16 || !f.hasChecksum || f.checksum != 0xdeadbeefcafe || len(f.events) != len(replayEvents) {
+		t.Fatalf("first frame: %d ms, checksum %v %x, %d events", f.elapsed, f.hasChecksum, f.checksum, len(f.events))
+	}
+	for i, want := range replayEvents {
+		if !reflect.DeepEqual(f.events[i], want) {
+			t.Errorf("event %d: got %#v, want %#v", i, f.events[i], want)
+		}
+	}
+
+	f, _ = p.nextFrame()
+	if f.elapsed != 1000 || f.hasChecksum || len(f.events) != 0 || !p.Done() {
+		t.Errorf("second frame: %d ms, checksum %v, %d events", f.elapsed, f.hasChecksum, len(f.events))
+	}
+}
+
+// Recordings from before MaxUpdates was saved still load.
+func TestLoadReplayVersion1(t *testing.T) {
+	var b []byte
+	b = append(b, replayMagic...)
+	b = binary.AppendUvarint(b, 1)
+	b = binary.AppendUvarint(b, 60)
+	// One frame, 16 ms, no checksum, a quit
+	b = binary.AppendUvarint(b, 16)
+	b = binary.AppendUvarint(b, 0)
+	b = binary.AppendUvarint(b, 1)
+	b = binary.AppendUvarint(b, sdl.QUIT)
+
+	path := writeGzip(t, b)
+	p, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UpdateRate != 60 || p.MaxUpdates != 0 || p.Len() != 1 {
+		t.Errorf("got rate %d, max %d, %d frames", p.UpdateRate, p.MaxUpdates, p.Len())
+	}
+
+	// Cut off in the middle of the frame
+	if _, err := LoadReplay(writeGzip(t, b[:len(b)-2])); err == nil {
+		t.Error("loaded a truncated recording")
+	}
+	if _, err := LoadReplay(writeGzip(t, []byte("NOTAREPLAY"))); err == nil {
+		t.Error("loaded something else")
+	}
+}
+
+func writeGzip(t *testing.T, data []byte) string {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	gz.Close()
+	f, err := os.CreateTemp(t.TempDir(), "*.rec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+// replayGame moves with the keys held and counts its updates.
+type replayGame struct {
+	x, updates float64
+	keys       int
+	drift      bool
+	frames     []int
+	sprite     *Sprite
+}
+
+func (g *replayGame) HandleEvent(e sdl.Event) bool {
+	switch e.(type) {
+	case *sdl.KeyDownEvent:
+		g.keys++
+	case *sdl.KeyUpEvent:
+		g.keys--
+	}
+	return true
+}
+
+func (g *replayGame) Update(dt float64) {
+	g.updates++
+	g.x += dt * float64(g.keys)
+	if g.drift && g.updates == 10 {
+		g.x += 1
+	}
+}
+
+func (g *replayGame) Draw(r *sdl.Renderer, alpha float64) error {
+	g.sprite.Animate()
+	g.frames = append(g.frames, g.sprite.Frame())
+	return nil
+}
+
+func (g *replayGame) Checksum() uint64 {
+	return HashValues(g.x, g.updates)
+}
+
+func newReplayGame(l *Loop) *replayGame {
+	s, _ := testSprite(4)
+	s.AddClip(NewClip("walk", []int{0, 1, 2, 3}, 20, PlayLoop))
+	s.Play("walk")
+	s.Time = l.Time
+	return &replayGame{sprite: s}
+}
+
+func TestLoopReplay(t *testing.T) {
+	w, err := NewHeadlessWindow("replay", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to run the loop with.", err)
+	}
+	defer w.Cleanup()
+
+	// A recording made by hand with uneven frame times, at settings the
+	// replaying loop doesn't start with
+	path := filepath.Join(t.TempDir(), "test.rec")
+	rec := NewLoop(50, 60)
+	rec.MaxUpdates = 2
+	r, err := NewRecorder(path, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newReplayGame(rec)
+	for i, ms := range []int{16, 40, 5, 100, 17, 16, 16, 33, 16, 16, 16, 16, 16} {
+		var events []sdl.Event
+		if i%4 == 0 {
+			events = append(events, &sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED})
+		}
+		for _, e := range events {
+			g.HandleEvent(e)
+		}
+		n, _ := rec.advance(ms)
+		for j := 0; j < n; j++ {
+			g.Update(rec.Step())
+		}
+		if err := r.frame(ms, events, g.Checksum(), n > 0); err != nil {
+			t.Fatal(err)
+		}
+		g.Draw(nil, 0)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoop(60, 60)
+	l.Clock.Time = NewManualTime()
+	l.Replay = p
+	replayed := newReplayGame(l)
+	if err := l.Run(w, replayed); err != nil {
+		t.Fatal(err)
+	}
+	if l.UpdateRate != 50 || l.MaxUpdates != 2 {
+		t.Errorf("replayed at rate %d, max %d, want 50, 2", l.UpdateRate, l.MaxUpdates)
+	}
+	if replayed.Checksum() != g.Checksum() || !p.Done() {
+		t.Errorf("replay ended at x %v after %v updates, want %v after %v", replayed.x, replayed.updates, g.x, g.updates)
+	}
+	if !reflect.DeepEqual(replayed.frames, g.frames) {
+		t.Errorf("animated %v, want %v", replayed.frames, g.frames)
+	}
+
+	// A game that doesn't do the same stops the replay
+	p.Rewind()
+	l = NewLoop(60, 60)
+	l.Clock.Time = NewManualTime()
+	l.Replay = p
+	drifting := newReplayGame(l)
+	drifting.drift = true
+	err = l.Run(w, drifting)
+	if desync, ok := err.(*DesyncError); !ok || desync.Frame >= p.Len() {
+		t.Errorf("got %v, want a desync", err)
+	}
+}
+
+func TestLoopTime(t *testing.T) {
+	l := NewLoop(50, 60)
+	for _, ms := range []int{16, 17, 500} {
+		l.advance(ms)
+	}
+	if l.Time.Ticks() != 533 {
+		t.Errorf("loop time at %d ms, want 533", l.Time.Ticks())
+	}
+}
+
+// keyGame keeps the key events it gets.
+type keyGame struct {
+	keys []uint32
+}
+
+func (g *keyGame) HandleEvent(e sdl.Event) bool {
+	if k, ok := e.(*sdl.KeyDownEvent); ok {
+		g.keys = append(g.keys, k.Keysym.Scancode)
+	}
+	return true
+}
+
+func (g *keyGame) Update(dt float64)                         {}
+func (g *keyGame) Draw(r *sdl.Renderer, alpha float64) error { return nil }
+
+// The inspector's F12 doesn't reach the game or the recording.
+func TestLoopRecordSkipsInspectorToggle(t *testing.T) {
+	w, err := NewHeadlessWindow("record", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to run the loop with.", err)
+	}
+	defer w.Cleanup()
+
+	path := filepath.Join(t.TempDir(), "test.rec")
+	l := NewLoop(60, 60)
+	l.Clock.Time = NewManualTime()
+	l.Frames = 1
+	l.Inspector = input.NewInspector(&bytes.Buffer{})
+	if l.Recorder, err = NewRecorder(path, l); err != nil {
+		t.Fatal(err)
+	}
+
+	f12 := sdl.Keysym{Scancode: sdl.SCANCODE_F12}
+	sdl.PushEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Keysym: f12})
+	sdl.PushEvent(&sdl.KeyUpEvent{Type: sdl.KEYUP, State: sdl.RELEASED, Keysym: f12})
+	sdl.PushEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_A}})
+	g := &keyGame{}
+	if err := l.Run(w, g); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !l.Inspector.Enabled {
+		t.Error("F12 didn't turn the inspector on")
+	}
+	if !reflect.DeepEqual(g.keys, []uint32{sdl.SCANCODE_A}) {
+		t.Errorf("game got keys %v, want only A", g.keys)
+	}
+	p, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := p.nextFrame()
+	for _, e := range f.events {
+		if k, ok := e.(*sdl.KeyDownEvent); ok && k.Keysym.Scancode == sdl.SCANCODE_F12 {
+			t.Error("recorded F12")
+		}
+		if k, ok := e.(*sdl.KeyUpEvent); ok && k.Keysym.Scancode == sdl.SCANCODE_F12 {
+			t.Error("recorded F12")
+		}
+	}
+}
+
+// Without an inspector, the default, F12 is the game's.
+func TestLoopNoInspector(t *testing.T) {
+	w, err := NewHeadlessWindow("inspector", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to run the loop with.", err)
+	}
+	defer w.Cleanup()
+
+	l := NewLoop(60, 60)
+	if l.Inspector != nil {
+		t.Fatal("NewLoop made an inspector")
+	}
+	l.Clock.Time = NewManualTime()
+	l.Frames = 1
+
+	sdl.PushEvent(&sdl.KeyDownEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_F12}})
+	g := &keyGame{}
+	if err := l.Run(w, g); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.keys, []uint32{sdl.SCANCODE_F12}) {
+		t.Errorf("game got keys %v, want F12", g.keys)
+	}
+}
@@ -0,0 +1,22 @@
+package engine
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// rwFromBytes is an RWops reading a copy of data. C can't hold on to Go
+// memory after a call returns (see cgo's pointer passing rules) and
+// RWFromMem keeps the pointer, so the bytes are copied to C memory. Call free
+// once the RWops is closed.
+func rwFromBytes(data []byte) (rw *sdl.RWops, free func(), err error) {
+	mem := C.CBytes(data)
+	rw = sdl.RWFromMem(mem, len(data))
+	if rw == nil {
+		C.free(mem)
+		return nil, nil, sdlError("SDL_RWFromMem")
+	}
+	return rw, func() { C.free(mem) }, nil
+}
@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"path"
+)
+
+// Frame rate for clips whose frames have no duration, like TexturePacker
+// exports. Aseprite uses 100ms frames by default too.
+const defaultClipFPS = 10.0
+
+// The JSON sprite sheet format shared by Aseprite and TexturePacker. Frames
+// are either a hash keyed by file name or an array with a "filename" field.
+// Aseprite adds a duration to each frame and tags to "meta".
+type sheetJSON struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string         `json:"image"`
+		FrameTags []frameTagJSON `json:"frameTags"`
+	} `json:"meta"`
+}
+
+type frameJSON struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X, Y, W, H int32
+	} `json:"frame"`
+	Rotated          bool `json:"rotated"`
+	SpriteSourceSize struct {
+		X, Y, W, H int32
+	} `json:"spriteSourceSize"`
+	SourceSize struct {
+		W, H int32
+	} `json:"sourceSize"`
+	Duration uint32 `json:"duration"`
+}
+
+type frameTagJSON struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+}
+
+// ParseSheetJSON reads an Aseprite or TexturePacker (hash or array) JSON
+// export and cuts texture into the frames it lists. It returns one clip per
+// Aseprite tag, with the frame durations from the file, plus a "default" clip
+// playing every frame in order.
+//
+// Rotated frames aren't supported, export with rotation turned off. Trimmed
+// frames are drawn where they were before trimming. The sheet's cell size is
+// the untrimmed size of the first frame.
+func ParseSheetJSON(data []byte, texture *sdl.Texture) (*SpriteSheet, []*Clip, error) {
+	var doc sheetJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	return doc.sheet(texture)
+}
+
+// sheet cuts texture into the frames of an already decoded export.
+func (doc *sheetJSON) sheet(texture *sdl.Texture) (*SpriteSheet, []*Clip, error) {
+	frames, err := parseFramesJSON(doc.Frames)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(frames) == 0 {
+		return nil, nil, errors.New("Sprite sheet JSON has no frames")
+	}
+
+	sheet := &SpriteSheet{
+		Texture: texture,
+		Cells:   make([]sdl.Rect, len(frames)),
+		Names:   make(map[string]int),
+		Trims:   make([]sdl.Rect, len(frames)),
+	}
+	durations := make([]uint32, len(frames))
+
+	for i, f := range frames {
+		if f.Rotated {
+			return nil, nil, errors.New(fmt.Sprintf("Sprite sheet frame %q is rotated, not supported", f.Filename))
+		}
+		sheet.Cells[i] = sdl.Rect{X: f.Frame.X, Y: f.Frame.Y, W: f.Frame.W, H: f.Frame.H}
+		sheet.Trims[i] = sdl.Rect{X: f.SpriteSourceSize.X, Y: f.SpriteSourceSize.Y, W: f.SourceSize.W, H: f.SourceSize.H}
+		sheet.Names[f.Filename] = i
+		durations[i] = f.Duration
+	}
+
+	first := sheet.Trim(0)
+	sheet.CellWidth, sheet.CellHeight = int(first.W), int(first.H)
+
+	all := NewClip("default", FrameRange(0, len(frames)), defaultClipFPS, PlayLoop)
+	all.Durations = clipDurations(all.Frames, durations)
+	clips := []*Clip{all}
+
+	for _, tag := range doc.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return nil, nil, errors.New(fmt.Sprintf("Sprite sheet tag %q has bad frames %d-%d", tag.Name, tag.From, tag.To))
+		}
+
+		clip := NewClip(tag.Name, FrameRange(tag.From, tag.To-tag.From+1), defaultClipFPS, PlayLoop)
+		switch tag.Direction {
+		case "reverse":
+			for i, j := 0, len(clip.Frames)-1; i < j; i, j = i+1, j-1 {
+				clip.Frames[i], clip.Frames[j] = clip.Frames[j], clip.Frames[i]
+			}
+		case "pingpong":
+			clip.Mode = PlayPingPong
+		}
+		clip.Durations = clipDurations(clip.Frames, durations)
+		clips = append(clips, clip)
+	}
+
+	return sheet, clips, nil
+}
+
+// parseFramesJSON reads frames from either layout. Hash keys are read in file
+// order, that's the frame order.
+func parseFramesJSON(raw json.RawMessage) ([]frameJSON, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var frames []frameJSON
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	var frames []frameJSON
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var f frameJSON
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		f.Filename = key.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// clipDurations picks the durations of a clip's frames. nil when the export
+// had none, the clip's FPS is used then.
+func clipDurations(frames []int, durations []uint32) []uint32 {
+	clip := make([]uint32, len(frames))
+	for i, frame := range frames {
+		if durations[frame] == 0 {
+			return nil
+		}
+		clip[i] = durations[frame]
+	}
+	return clip
+}
+
+// SpriteSheet loads an Aseprite or TexturePacker JSON export and the image it
+// names (relative to the JSON file). See ParseSheetJSON. The sheet's texture
+// holds a reference, Release(sheet.Texture) when done.
+func (a *Assets) SpriteSheet(jsonPath string) (*SpriteSheet, []*Clip, error) {
+	data, err := a.ReadFile(jsonPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc sheetJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Meta.Image == "" {
+		return nil, nil, errors.New(fmt.Sprintf("%s doesn't name its image", jsonPath))
+	}
+
+	// Asset paths are slash separated, like the ones in the export
+	texture, err := a.Texture(path.Join(path.Dir(jsonPath), doc.Meta.Image))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sheet, clips, err := doc.sheet(texture)
+	if err != nil {
+		a.Release(texture)
+		return nil, nil, err
+	}
+	return sheet, clips, nil
+}
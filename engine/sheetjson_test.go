@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"reflect"
+	"testing"
+)
+
+// Two 32x32 frames, TexturePacker array style. The second is trimmed to the
+// 10x20 in its middle.
+const trimmedSheetJSON = `{"frames": [
+	{"filename": "a.png", "frame": {"x": 0, "y": 0, "w": 32, "h": 32},
+	 "rotated": false, "trimmed": false,
+	 "spriteSourceSize": {"x": 0, "y": 0, "w": 32, "h": 32}, "sourceSize": {"w": 32, "h": 32}},
+	{"filename": "b.png", "frame": {"x": 32, "y": 0, "w": 10, "h": 20},
+	 "rotated": false, "trimmed": true,
+	 "spriteSourceSize": {"x": 4, "y": 6, "w": 10, "h": 20}, "sourceSize": {"w": 32, "h": 32}}
+], "meta": {"image": "sheet.png"}}`
+
+func TestParseSheetJSONTrimmed(t *testing.T) {
+	sheet, clips, err := ParseSheetJSON([]byte(trimmedSheetJSON), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.CellWidth != 32 || sheet.CellHeight != 32 || len(clips) != 1 {
+		t.Fatalf("got %dx%d cells and %d clips, want 32x32 and 1", sheet.CellWidth, sheet.CellHeight, len(clips))
+	}
+	if got, want := sheet.Trim(1), (sdl.Rect{X: 4, Y: 6, W: 32, H: 32}); got != want {
+		t.Errorf("Trim(1) = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name          string
+		frame         int
+		width, height int
+		flip          sdl.RendererFlip
+		dst           sdl.Rect
+	}{
+		{"untrimmed", 0, 32, 32, sdl.FLIP_NONE, sdl.Rect{X: 100, Y: 50, W: 32, H: 32}},
+		{"trimmed", 1, 32, 32, sdl.FLIP_NONE, sdl.Rect{X: 104, Y: 56, W: 10, H: 20}},
+		{"trimmed and scaled", 1, 64, 64, sdl.FLIP_NONE, sdl.Rect{X: 108, Y: 62, W: 20, H: 40}},
+		{"trimmed and mirrored", 1, 32, 32, sdl.FLIP_HORIZONTAL, sdl.Rect{X: 118, Y: 56, W: 10, H: 20}},
+	}
+	for _, test := range tests {
+		s := NewSprite(sheet, 10)
+		s.Time = NewManualTime()
+		clip := NewClip("one", []int{test.frame}, 10, PlayLoop)
+		clip.Flip = test.flip
+		s.AddClip(clip)
+		s.Play("one")
+		s.Width, s.Height = test.width, test.height
+		s.DestX, s.DestY = 100, 50
+
+		cmd := s.drawCommand(1)
+		if cmd.Dst != test.dst || cmd.Src != sheet.Rect(test.frame) {
+			t.Errorf("%s: drawn %v from %v, want %v from %v", test.name, cmd.Dst, cmd.Src, test.dst, sheet.Rect(test.frame))
+		}
+	}
+}
+
+// Four 16x16 frames, Aseprite hash style. Keys aren't in alphabetical order,
+// the file's order is the frame order.
+const asepriteSheetJSON = `{"frames": {
+	"yoshi 2.aseprite": {"frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": false,
+		"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 100},
+	"yoshi 10.aseprite": {"frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": false,
+		"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 50},
+	"yoshi 1.aseprite": {"frame": {"x": 32, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": false,
+		"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 200},
+	"yoshi 3.aseprite": {"frame": {"x": 48, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": false,
+		"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 100}
+}, "meta": {"app": "http://www.aseprite.org/", "image": "yoshi.png", "frameTags": [
+	{"name": "run", "from": 0, "to": 2, "direction": "forward"},
+	{"name": "back", "from": 1, "to": 3, "direction": "reverse"},
+	{"name": "bounce", "from": 0, "to": 3, "direction": "pingpong"}
+]}}`
+
+func TestParseSheetJSONAseprite(t *testing.T) {
+	sheet, clips, err := ParseSheetJSON([]byte(asepriteSheetJSON), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"yoshi 2.aseprite", "yoshi 10.aseprite", "yoshi 1.aseprite", "yoshi 3.aseprite"}
+	for i, name := range names {
+		if got, ok := sheet.Index(name); !ok || got != i {
+			t.Errorf("Index(%q) = %d, %v, want %d", name, got, ok, i)
+		}
+		if got, want := sheet.Rect(i), (sdl.Rect{X: int32(16 * i), W: 16, H: 16}); got != want {
+			t.Errorf("Rect(%d) = %v, want %v", i, got, want)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		frames    []int
+		durations []uint32
+		mode      PlayMode
+	}{
+		{"default", []int{0, 1, 2, 3}, []uint32{100, 50, 200, 100}, PlayLoop},
+		{"run", []int{0, 1, 2}, []uint32{100, 50, 200}, PlayLoop},
+		{"back", []int{3, 2, 1}, []uint32{100, 200, 50}, PlayLoop},
+		{"bounce", []int{0, 1, 2, 3}, []uint32{100, 50, 200, 100}, PlayPingPong},
+	}
+	if len(clips) != len(tests) {
+		t.Fatalf("got %d clips, want %d", len(clips), len(tests))
+	}
+	for i, test := range tests {
+		clip := clips[i]
+		if clip.Name != test.name || clip.Mode != test.mode ||
+			!reflect.DeepEqual(clip.Frames, test.frames) || !reflect.DeepEqual(clip.Durations, test.durations) {
+			t.Errorf("%s: got %s %v frames %v durations %v, want %v frames %v durations %v", test.name,
+				clip.Name, clip.Mode, clip.Frames, clip.Durations, test.mode, test.frames, test.durations)
+		}
+	}
+}
+
+func TestParseSheetJSONNoDurations(t *testing.T) {
+	// TexturePacker doesn't export durations, clips play at their FPS
+	_, clips, err := ParseSheetJSON([]byte(trimmedSheetJSON), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clips[0].Durations != nil || clips[0].FPS != defaultClipFPS {
+		t.Errorf("got durations %v at %v FPS, want none at %v", clips[0].Durations, clips[0].FPS, defaultClipFPS)
+	}
+}
+
+func TestParseSheetJSONErrors(t *testing.T) {
+	frame := `{"frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "duration": 100}`
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"malformed", `{"frames": {`},
+		{"no frames", `{"frames": {}, "meta": {}}`},
+		{"bad frame", `{"frames": {"a": []}}`},
+		{"rotated", `{"frames": [{"filename": "a", "rotated": true}]}`},
+		{"tag past the end", `{"frames": {"a": ` + frame + `}, "meta": {"frameTags": [{"name": "t", "from": 0, "to": 1}]}}`},
+		{"tag backwards", `{"frames": {"a": ` + frame + `, "b": ` + frame + `}, "meta": {"frameTags": [{"name": "t", "from": 1, "to": 0}]}}`},
+	}
+	for _, test := range tests {
+		if _, _, err := ParseSheetJSON([]byte(test.json), nil); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
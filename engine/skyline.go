@@ -0,0 +1,100 @@
+package engine
+
+// skyline packs rectangles into a fixed size page, bottom-left first. It keeps
+// the top edge ("skyline") of everything placed so far as a list of
+// horizontal segments and puts each new rectangle where it ends up lowest.
+//
+// See Jukka Jylänki, "A Thousand Ways to Pack the Bin".
+type skyline struct {
+	width, height int
+	nodes         []skylineNode
+}
+
+// skylineNode is a segment of the skyline: from x to x + w at height y.
+type skylineNode struct {
+	x, y, w int
+}
+
+func newSkyline(width, height int) *skyline {
+	return &skyline{
+		width:  width,
+		height: height,
+		nodes:  []skylineNode{{0, 0, width}},
+	}
+}
+
+// insert finds room for a w x h rectangle and returns its top left corner.
+// ok is false when the page is full.
+func (s *skyline) insert(w, h int) (x, y int, ok bool) {
+	best := -1
+	bestY, bestWidth := s.height, s.width
+	for i := range s.nodes {
+		fitY, fits := s.fit(i, w, h)
+		if !fits {
+			continue
+		}
+		// Lowest spot, then the narrowest segment to waste less space
+		if fitY < bestY || (fitY == bestY && s.nodes[i].w < bestWidth) {
+			best, bestY, bestWidth = i, fitY, s.nodes[i].w
+		}
+	}
+	if best < 0 {
+		return 0, 0, false
+	}
+
+	x = s.nodes[best].x
+	s.place(best, skylineNode{x, bestY + h, w})
+	return x, bestY, true
+}
+
+// fit returns how low a w x h rectangle sits when its left edge is at node i.
+func (s *skyline) fit(i, w, h int) (int, bool) {
+	if s.nodes[i].x+w > s.width {
+		return 0, false
+	}
+
+	y := 0
+	widthLeft := w
+	for j := i; widthLeft > 0; j++ {
+		if s.nodes[j].y > y {
+			y = s.nodes[j].y
+		}
+		if y+h > s.height {
+			return 0, false
+		}
+		widthLeft -= s.nodes[j].w
+	}
+	return y, true
+}
+
+// place adds node at index i and trims the segments now underneath it.
+func (s *skyline) place(i int, node skylineNode) {
+	s.nodes = append(s.nodes, skylineNode{})
+	copy(s.nodes[i+1:], s.nodes[i:])
+	s.nodes[i] = node
+
+	for j := i + 1; j < len(s.nodes); {
+		prev := s.nodes[j-1]
+		overlap := prev.x + prev.w - s.nodes[j].x
+		if overlap <= 0 {
+			break
+		}
+
+		s.nodes[j].x += overlap
+		s.nodes[j].w -= overlap
+		if s.nodes[j].w > 0 {
+			break
+		}
+		s.nodes = append(s.nodes[:j], s.nodes[j+1:]...)
+	}
+
+	// Neighbours at the same height become one segment
+	for j := 0; j < len(s.nodes)-1; {
+		if s.nodes[j].y == s.nodes[j+1].y {
+			s.nodes[j].w += s.nodes[j+1].w
+			s.nodes = append(s.nodes[:j+1], s.nodes[j+2:]...)
+		} else {
+			j++
+		}
+	}
+}
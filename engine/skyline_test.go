@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSkylineInsert(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizes [][2]int
+		want  [][2]int // top left corners, -1 when it doesn't fit
+	}{
+		{"first at the top left", [][2]int{{30, 40}}, [][2]int{{0, 0}}},
+		{"next to the first", [][2]int{{30, 40}, {30, 20}}, [][2]int{{0, 0}, {30, 0}}},
+		{"lowest spot wins", [][2]int{{60, 40}, {40, 10}, {40, 10}}, [][2]int{{0, 0}, {60, 0}, {60, 10}}},
+		{"under the tallest", [][2]int{{50, 90}, {50, 50}, {50, 50}}, [][2]int{{0, 0}, {50, 0}, {50, 50}}},
+		{"exactly the page", [][2]int{{100, 100}}, [][2]int{{0, 0}}},
+		{"too wide", [][2]int{{101, 10}}, [][2]int{{-1, -1}}},
+		{"too tall", [][2]int{{10, 101}}, [][2]int{{-1, -1}}},
+		{"page full", [][2]int{{100, 60}, {100, 60}, {100, 40}}, [][2]int{{0, 0}, {-1, -1}, {0, 60}}},
+	}
+	for _, test := range tests {
+		s := newSkyline(100, 100)
+		for i, size := range test.sizes {
+			x, y, ok := s.insert(size[0], size[1])
+			want := test.want[i]
+			if ok != (want[0] >= 0) || (ok && (x != want[0] || y != want[1])) {
+				t.Errorf("%s: %dx%d at %d,%d (%v), want %d,%d",
+					test.name, size[0], size[1], x, y, ok, want[0], want[1])
+			}
+		}
+	}
+}
+
+// Whatever comes in, nothing overlaps or sticks out of the page.
+func TestSkylineNoOverlap(t *testing.T) {
+	s := newSkyline(256, 256)
+	var placed []image.Rectangle
+	for i := 0; i < 200; i++ {
+		w, h := 5+(i*7)%40, 5+(i*13)%30
+		x, y, ok := s.insert(w, h)
+		if !ok {
+			continue
+		}
+		r := image.Rect(x, y, x+w, y+h)
+		if !r.In(image.Rect(0, 0, 256, 256)) {
+			t.Fatalf("%v is off the page", r)
+		}
+		for _, other := range placed {
+			if r.Overlaps(other) {
+				t.Fatalf("%v overlaps %v", r, other)
+			}
+		}
+		placed = append(placed, r)
+	}
+	if len(placed) < 50 {
+		t.Errorf("only %d rects fit", len(placed))
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Sprite is an animated image drawn from a sprite sheet. Game entities such as
+// a Protagonist embed a *Sprite and add their own Update().
+//
+// A sprite has any number of named clips and plays one at a time. NewSprite
+// adds a "default" clip looping over the whole sheet.
+//
+// When driven by a fixed step Loop, call SavePosition() before moving the
+// sprite in Update() so Draw() can interpolate between the two positions.
+type Sprite struct {
+
+	// Where the animation frames come from
+	Sheet *SpriteSheet
+
+	// Where to draw the sprite
+	DestX, DestY int32
+	prevX, prevY int32
+	hasPrev      bool
+
+	// Size drawn on screen, the sheet's cell size by default. Frames of
+	// other sizes are scaled the same.
+	Width, Height int
+
+	// animation info
+	Clips    map[string]*Clip
+	clip     *Clip
+	frame    int // index into clip.Frames
+	step     int // 1 or -1, ping-pong clips go both ways
+	lastTick uint32
+	started  bool
+	finished bool
+
+	// Called when a PlayOnce clip is done showing its last frame
+	OnClipEnd func(clip string)
+
+	// Where animation time comes from. nil means SDLTime.
+	Time TimeSource
+}
+
+func NewSprite(sheet *SpriteSheet, animFPS float64) *Sprite {
+	s := &Sprite{
+		Sheet:  sheet,
+		Width:  sheet.CellWidth,
+		Height: sheet.CellHeight,
+		Clips:  make(map[string]*Clip),
+		Time:   SDLTime{},
+	}
+	s.AddClip(NewClip("default", FrameRange(0, sheet.Len()), animFPS, PlayLoop))
+	s.Play("default")
+	return s
+}
+
+// AddClip makes clip available to Play().
+func (s *Sprite) AddClip(clip *Clip) {
+	s.Clips[clip.Name] = clip
+}
+
+// Play switches to the named clip and starts it from the first frame. Playing
+// the clip that is already playing does nothing, so it's fine to call every
+// update.
+func (s *Sprite) Play(name string) error {
+	clip, ok := s.Clips[name]
+	if !ok {
+		return errors.New(fmt.Sprintf("Sprite has no clip %q", name))
+	}
+	if clip == s.clip {
+		return nil
+	}
+
+	s.clip = clip
+	s.Restart()
+	return nil
+}
+
+// Restart plays the current clip again from the first frame.
+func (s *Sprite) Restart() {
+	s.frame = 0
+	s.step = 1
+	s.started = false
+	s.finished = false
+}
+
+// Clip is the name of the clip playing.
+func (s *Sprite) Clip() string {
+	return s.clip.Name
+}
+
+// Finished is true once a PlayOnce clip showed its last frame for the
+// frame's whole duration. The sprite stays on that frame.
+func (s *Sprite) Finished() bool {
+	return s.finished
+}
+
+// Frame is the sheet cell that will be drawn next.
+func (s *Sprite) Frame() int {
+	return s.clip.Frames[s.frame]
+}
+
+// Animate moves through the clip's frames as their durations pass. Draw()
+// calls it, only call it directly when stepping the animation without
+// rendering.
+func (s *Sprite) Animate() {
+	now := timeSource(s.Time).Ticks()
+	if !s.started {
+		s.started = true
+		s.lastTick = now
+		return
+	}
+
+	// OnClipEnd may Play() another clip, which restarts the animation
+	for s.started && !s.finished {
+		duration := s.clip.Duration(s.frame)
+		if duration == 0 || now-s.lastTick < duration {
+			// Frames without a duration stay up for good
+			break
+		}
+		s.lastTick += duration
+		s.advance()
+	}
+}
+
+// advance moves to the next frame according to the clip's play mode.
+func (s *Sprite) advance() {
+	last := len(s.clip.Frames) - 1
+
+	switch s.clip.Mode {
+	case PlayLoop:
+		s.frame = (s.frame + 1) % len(s.clip.Frames)
+
+	case PlayOnce:
+		if s.frame < last {
+			s.frame += 1
+			return
+		}
+		// The last frame's time is up
+		s.finished = true
+		if s.OnClipEnd != nil {
+			s.OnClipEnd(s.clip.Name)
+		}
+
+	case PlayPingPong:
+		if last == 0 {
+			return
+		}
+		if s.frame+s.step < 0 || s.frame+s.step > last {
+			s.step = -s.step
+		}
+		s.frame += s.step
+	}
+}
+
+// Center is the middle of the sprite, so a Camera can follow it.
+func (s *Sprite) Center() (float64, float64) {
+	return float64(s.DestX) + float64(s.Width)/2, float64(s.DestY) + float64(s.Height)/2
+}
+
+// SavePosition remembers the current position as the one Draw()
+// interpolates from.
+func (s *Sprite) SavePosition() {
+	s.prevX, s.prevY = s.DestX, s.DestY
+	s.hasPrev = true
+}
+
+// Draw renders the current frame. alpha is the Loop's interpolation alpha,
+// pass 1 when not using a fixed step loop.
+func (s *Sprite) Draw(r *sdl.Renderer, alpha float64) error {
+	cmd := s.drawCommand(alpha)
+	if cmd.Flip != sdl.FLIP_NONE {
+		return CopyEx(r, cmd.Texture, &cmd.Src, &cmd.Dst, 0, nil, cmd.Flip)
+	}
+	return Copy(r, cmd.Texture, &cmd.Src, &cmd.Dst)
+}
+
+// Submit is Draw for a RenderQueue: the current frame is queued on layer at
+// depth z.
+func (s *Sprite) Submit(q *RenderQueue, layer int, z float64, alpha float64) {
+	cmd := s.drawCommand(alpha)
+	cmd.Layer = layer
+	cmd.Z = z
+	q.Submit(cmd)
+}
+
+// drawCommand animates and works out what to copy where.
+func (s *Sprite) drawCommand(alpha float64) DrawCommand {
+	s.Animate()
+
+	// Rect for placement on screen (dest rect)
+	x, y := s.DestX, s.DestY
+	if s.hasPrev {
+		x = s.prevX + int32(float64(s.DestX-s.prevX)*alpha)
+		y = s.prevY + int32(float64(s.DestY-s.prevY)*alpha)
+	}
+
+	// Each frame is drawn at its own size, scaled like the sheet's cell size
+	// is to the sprite's, and offset by what was trimmed off it
+	frame := s.Frame()
+	src := s.Sheet.Rect(frame)
+	trim := s.Sheet.Trim(frame)
+	if s.clip.Flip&sdl.FLIP_HORIZONTAL != 0 {
+		trim.X = trim.W - trim.X - src.W
+	}
+	if s.clip.Flip&sdl.FLIP_VERTICAL != 0 {
+		trim.Y = trim.H - trim.Y - src.H
+	}
+	scaleX, scaleY := 1.0, 1.0
+	if s.Sheet.CellWidth > 0 && s.Sheet.CellHeight > 0 {
+		scaleX = float64(s.Width) / float64(s.Sheet.CellWidth)
+		scaleY = float64(s.Height) / float64(s.Sheet.CellHeight)
+	}
+
+	return DrawCommand{
+		Texture: s.Sheet.Texture,
+		Src:     src,
+		Dst: sdl.Rect{
+			X: x + int32(float64(trim.X)*scaleX),
+			Y: y + int32(float64(trim.Y)*scaleY),
+			W: int32(float64(src.W) * scaleX),
+			H: int32(float64(src.H) * scaleY),
+		},
+		Flip: s.clip.Flip,
+	}
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"testing"
+)
+
+// testSprite has a sheet of n 8x8 cells, no texture, and a fake clock.
+func testSprite(n int) (*Sprite, *ManualTime) {
+	sheet := &SpriteSheet{CellWidth: 8, CellHeight: 8, Cells: make([]sdl.Rect, n)}
+	fake := NewManualTime()
+	s := NewSprite(sheet, 10)
+	s.Time = fake
+	return s, fake
+}
+
+func TestSpritePlayOnceShowsLastFrame(t *testing.T) {
+	s, fake := testSprite(3)
+	clip := NewClip("land", []int{0, 1, 2}, 10, PlayOnce)
+	clip.Durations = []uint32{50, 50, 100}
+	s.AddClip(clip)
+	s.Play("land")
+
+	ended := 0
+	s.OnClipEnd = func(name string) { ended++ }
+
+	steps := []struct {
+		at       uint32
+		frame    int
+		finished bool
+	}{
+		{0, 0, false},
+		{49, 0, false},
+		{50, 1, false},
+		{100, 2, false},
+		// The last frame is up for its whole 100 ms
+		{199, 2, false},
+		{200, 2, true},
+		{1000, 2, true},
+	}
+	for _, step := range steps {
+		fake.Now = step.at
+		s.Animate()
+		if s.Frame() != step.frame || s.Finished() != step.finished {
+			t.Errorf("at %d ms: frame %d, finished %v, want %d, %v",
+				step.at, s.Frame(), s.Finished(), step.frame, step.finished)
+		}
+	}
+	if ended != 1 {
+		t.Errorf("OnClipEnd called %d times, want 1", ended)
+	}
+}
+
+func TestSpriteZeroDurationStops(t *testing.T) {
+	tests := []struct {
+		name string
+		clip *Clip
+	}{
+		{"FPS 0", NewClip("still", []int{0, 1}, 0, PlayLoop)},
+		{"negative FPS", NewClip("still", []int{0, 1}, -5, PlayLoop)},
+		{"0 ms frame", &Clip{Name: "still", Frames: []int{0, 1}, Durations: []uint32{0, 0}, FPS: 10}},
+	}
+	for _, test := range tests {
+		s, fake := testSprite(2)
+		s.AddClip(test.clip)
+		s.Play("still")
+
+		// Would spin forever if a 0 ms frame were ever left
+		s.Animate()
+		fake.Now = 5000
+		s.Animate()
+		if s.Frame() != 0 {
+			t.Errorf("%s: on frame %d, want 0", test.name, s.Frame())
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SpriteSheet is a texture cut into a grid of equally sized cells:
+//
+//	margin
+//	  +------+ spacing +------+
+//	  | 0, 0 |         | 0, 1 |  ...
+//	  +------+         +------+
+//	  spacing
+//	  +------+         +------+
+//	  | 1, 0 |         | 1, 1 |  ...
+//
+// Cells are numbered row by row, left to right, starting at 0.
+//
+// Sheets exported by tools (see ParseSheetJSON and Assets.SpriteSheet)
+// aren't grids, every frame has its own rect. Those are listed in Cells,
+// which replaces the grid layout when set.
+type SpriteSheet struct {
+	Texture *sdl.Texture
+
+	// Size of one cell
+	CellWidth, CellHeight int
+
+	// Pixels around the whole grid
+	Margin int
+
+	// Pixels between two cells
+	Spacing int
+
+	// Grid layout
+	Columns, Rows int
+
+	// Explicit cell rects, and the names they were exported with
+	Cells []sdl.Rect
+	Names map[string]int
+
+	// Exports can trim the empty edges off frames. Trims[i] is where cell i
+	// goes in its untrimmed frame: X, Y is the offset and W, H the untrimmed
+	// size. Cells without one fill their whole frame.
+	Trims []sdl.Rect
+}
+
+// NewSpriteSheet works out how many rows and columns fit in the texture.
+func NewSpriteSheet(t *sdl.Texture, cellWidth, cellHeight, margin, spacing int) (*SpriteSheet, error) {
+	if cellWidth < 1 || cellHeight < 1 {
+		return nil, errors.New(fmt.Sprintf("Bad sprite sheet cell size %dx%d", cellWidth, cellHeight))
+	}
+	if margin < 0 || spacing < 0 {
+		return nil, errors.New(fmt.Sprintf("Bad sprite sheet margin %d or spacing %d", margin, spacing))
+	}
+
+	w, h, err := QueryTexture(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// n cells take n * cell + (n - 1) * spacing pixels
+	columns := (w - 2*margin + spacing) / (cellWidth + spacing)
+	rows := (h - 2*margin + spacing) / (cellHeight + spacing)
+	if columns < 1 || rows < 1 {
+		return nil, errors.New(fmt.Sprintf(
+			"Sprite sheet of %dx%d cells doesn't fit a %dx%d texture", cellWidth, cellHeight, w, h))
+	}
+
+	s, err := NewSpriteSheetGrid(t, cellWidth, cellHeight, columns, rows)
+	if err != nil {
+		return nil, err
+	}
+	s.Margin = margin
+	s.Spacing = spacing
+	return s, nil
+}
+
+// NewSpriteSheetGrid is for sheets with a known layout and no margin or
+// spacing, e.g. a single column of animation frames.
+func NewSpriteSheetGrid(t *sdl.Texture, cellWidth, cellHeight, columns, rows int) (*SpriteSheet, error) {
+	if cellWidth < 1 || cellHeight < 1 || columns < 1 || rows < 1 {
+		return nil, errors.New(fmt.Sprintf(
+			"Bad sprite sheet grid: %dx%d cells, %d columns, %d rows", cellWidth, cellHeight, columns, rows))
+	}
+	return &SpriteSheet{
+		Texture:    t,
+		CellWidth:  cellWidth,
+		CellHeight: cellHeight,
+		Columns:    columns,
+		Rows:       rows,
+	}, nil
+}
+
+// Len is the number of cells in the sheet.
+func (s *SpriteSheet) Len() int {
+	if s.Cells != nil {
+		return len(s.Cells)
+	}
+	return s.Columns * s.Rows
+}
+
+// Rect is the source rect of cell index.
+func (s *SpriteSheet) Rect(index int) sdl.Rect {
+	if s.Cells != nil {
+		return s.Cells[index]
+	}
+	return s.RectAt(index/s.Columns, index%s.Columns)
+}
+
+// Trim is where cell index goes in its untrimmed frame, see Trims.
+func (s *SpriteSheet) Trim(index int) sdl.Rect {
+	if index < len(s.Trims) && s.Trims[index].W > 0 && s.Trims[index].H > 0 {
+		return s.Trims[index]
+	}
+	cell := s.Rect(index)
+	return sdl.Rect{W: cell.W, H: cell.H}
+}
+
+// Index is the cell exported under name, e.g. "walk_01.png".
+func (s *SpriteSheet) Index(name string) (int, bool) {
+	i, ok := s.Names[name]
+	return i, ok
+}
+
+// RectAt is the source rect of the cell at row, col of a grid sheet.
+func (s *SpriteSheet) RectAt(row, col int) sdl.Rect {
+	return sdl.Rect{
+		X: int32(s.Margin + col*(s.CellWidth+s.Spacing)),
+		Y: int32(s.Margin + row*(s.CellHeight+s.Spacing)),
+		W: int32(s.CellWidth),
+		H: int32(s.CellHeight),
+	}
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"testing"
+)
+
+func TestNewSpriteSheetGrid(t *testing.T) {
+	bad := [][4]int{
+		{0, 64, 1, 8},
+		{64, 0, 1, 8},
+		{64, 64, 0, 8},
+		{64, 64, 1, -1},
+	}
+	for _, args := range bad {
+		if _, err := NewSpriteSheetGrid(nil, args[0], args[1], args[2], args[3]); err == nil {
+			t.Errorf("NewSpriteSheetGrid%v: no error", args)
+		}
+	}
+
+	s, err := NewSpriteSheetGrid(nil, 64, 32, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 6 {
+		t.Errorf("Len() = %d, want 6", s.Len())
+	}
+	if got, want := s.Rect(3), (sdl.Rect{X: 64, Y: 32, W: 64, H: 32}); got != want {
+		t.Errorf("Rect(3) = %v, want %v", got, want)
+	}
+}
+
+func TestNewSpriteSheetBadArgs(t *testing.T) {
+	// Checked before the texture is looked at, nil is never queried
+	bad := [][4]int{
+		{0, 16, 0, 0},
+		{16, -1, 0, 0},
+		{16, 16, -1, 0},
+		{16, 16, 0, -1},
+		{16, 16, 0, -16}, // used to divide by zero
+		{16, 16, 0, -17},
+	}
+	for _, args := range bad {
+		if _, err := NewSpriteSheet(nil, args[0], args[1], args[2], args[3]); err == nil {
+			t.Errorf("NewSpriteSheet%v: no error", args)
+		}
+	}
+}
+
+func TestSpriteSheetRectAt(t *testing.T) {
+	s, err := NewSpriteSheetGrid(nil, 16, 24, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Margin = 2
+	s.Spacing = 1
+
+	tests := []struct {
+		row, col int
+		want     sdl.Rect
+	}{
+		{0, 0, sdl.Rect{X: 2, Y: 2, W: 16, H: 24}},
+		{0, 1, sdl.Rect{X: 19, Y: 2, W: 16, H: 24}},
+		{1, 0, sdl.Rect{X: 2, Y: 27, W: 16, H: 24}},
+		{2, 3, sdl.Rect{X: 53, Y: 52, W: 16, H: 24}},
+	}
+	for _, test := range tests {
+		if got := s.RectAt(test.row, test.col); got != test.want {
+			t.Errorf("RectAt(%d, %d) = %v, want %v", test.row, test.col, got, test.want)
+		}
+	}
+
+	// Rect numbers the same cells row by row
+	if got, want := s.Rect(7), s.RectAt(1, 3); got != want {
+		t.Errorf("Rect(7) = %v, want %v", got, want)
+	}
+}
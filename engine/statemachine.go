@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AnimParams are the entity fields an AnimStateMachine's transitions look at.
+// Update() sets them, e.g. params.Set("speed", 200) or
+// params.SetBool("grounded", true).
+type AnimParams map[string]float64
+
+func (p AnimParams) Set(name string, value float64) {
+	p[name] = value
+}
+
+func (p AnimParams) SetBool(name string, value bool) {
+	if value {
+		p[name] = 1
+	} else {
+		p[name] = 0
+	}
+}
+
+func (p AnimParams) Get(name string) float64 {
+	return p[name]
+}
+
+func (p AnimParams) Bool(name string) bool {
+	return p[name] != 0
+}
+
+// AnimTransition moves the machine from one state to another.
+type AnimTransition struct {
+	From, To string
+
+	// Take the transition when this returns true. nil always passes.
+	When func(p AnimParams) bool
+
+	// Only take the transition once the From clip finished. For PlayOnce
+	// clips like "land" going back to "idle".
+	OnClipEnd bool
+}
+
+// AnimStateMachine picks which clip a Sprite plays. Each state plays one clip
+// and transitions between states are guarded by conditions on AnimParams:
+//
+//	m := NewAnimStateMachine(yoshi.Sprite, "idle")
+//	m.AddState("idle", "idle")
+//	m.AddState("run", "run")
+//	m.AddTransition(AnimTransition{From: "idle", To: "run",
+//		When: func(p AnimParams) bool { return p.Get("speed") > 0 }})
+//
+// Call Update() after setting the params, every game update. Transitions are
+// checked in the order they were added, the first one that passes is taken.
+// From "*" matches any state.
+type AnimStateMachine struct {
+	Params AnimParams
+
+	sprite      *Sprite
+	states      map[string]string // state -> clip
+	transitions []AnimTransition
+	current     string
+}
+
+func NewAnimStateMachine(s *Sprite, initial string) *AnimStateMachine {
+	return &AnimStateMachine{
+		Params:  make(AnimParams),
+		sprite:  s,
+		states:  make(map[string]string),
+		current: initial,
+	}
+}
+
+// AddState adds a state that plays clip.
+func (m *AnimStateMachine) AddState(name, clip string) {
+	m.states[name] = clip
+}
+
+func (m *AnimStateMachine) AddTransition(t AnimTransition) {
+	m.transitions = append(m.transitions, t)
+}
+
+// State is the current state's name.
+func (m *AnimStateMachine) State() string {
+	return m.current
+}
+
+// Update takes the first transition out of the current state that passes and
+// makes the sprite play the new state's clip.
+func (m *AnimStateMachine) Update() error {
+	for _, t := range m.transitions {
+		if t.From != m.current && t.From != "*" {
+			continue
+		}
+		if t.To == m.current {
+			continue
+		}
+		if t.OnClipEnd && !m.sprite.Finished() {
+			continue
+		}
+		if t.When != nil && !t.When(m.Params) {
+			continue
+		}
+
+		m.current = t.To
+		break
+	}
+
+	clip, ok := m.states[m.current]
+	if !ok {
+		return errors.New(fmt.Sprintf("Animation state machine has no state %q", m.current))
+	}
+	return m.sprite.Play(clip)
+}
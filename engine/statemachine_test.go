@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestAnimStateMachineOnClipEnd(t *testing.T) {
+	s, fake := testSprite(4)
+	land := NewClip("land", []int{0, 1, 2}, 10, PlayOnce)
+	land.Durations = []uint32{50, 50, 100}
+	s.AddClip(land)
+	s.AddClip(NewClip("idle", []int{3}, 10, PlayLoop))
+
+	m := NewAnimStateMachine(s, "land")
+	m.AddState("land", "land")
+	m.AddState("idle", "idle")
+	m.AddTransition(AnimTransition{From: "land", To: "idle", OnClipEnd: true})
+
+	steps := []struct {
+		at    uint32
+		state string
+		frame int
+	}{
+		{0, "land", 0},
+		{60, "land", 1},
+		{100, "land", 2},
+		// Last frame of land drawn for its full 100 ms
+		{150, "land", 2},
+		{199, "land", 2},
+		// Draw finds the clip over, the next update moves on
+		{200, "land", 2},
+		{216, "idle", 3},
+	}
+	for _, step := range steps {
+		fake.Now = step.at
+		// As in a game: update, then draw
+		if err := m.Update(); err != nil {
+			t.Fatal(err)
+		}
+		s.Animate()
+		if m.State() != step.state || s.Frame() != step.frame {
+			t.Errorf("at %d ms: state %s on frame %d, want %s on %d",
+				step.at, m.State(), s.Frame(), step.state, step.frame)
+		}
+	}
+}
+
+// when passes while the bool param name is set.
+func when(name string) func(AnimParams) bool {
+	return func(p AnimParams) bool { return p.Bool(name) }
+}
+
+func TestAnimStateMachineTransitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        string
+		transitions []AnimTransition
+		params      []string
+		want        string
+	}{
+		{"guard fails", "idle",
+			[]AnimTransition{{From: "idle", To: "run", When: when("run")}},
+			nil, "idle"},
+		{"guard passes", "idle",
+			[]AnimTransition{{From: "idle", To: "run", When: when("run")}},
+			[]string{"run"}, "run"},
+		{"no guard", "idle",
+			[]AnimTransition{{From: "idle", To: "run"}},
+			nil, "run"},
+		{"other state's transition", "jump",
+			[]AnimTransition{{From: "idle", To: "run"}},
+			nil, "jump"},
+		{"any state", "jump",
+			[]AnimTransition{{From: "*", To: "hurt", When: when("hurt")}},
+			[]string{"hurt"}, "hurt"},
+		{"any state guard fails", "jump",
+			[]AnimTransition{{From: "*", To: "hurt", When: when("hurt")}},
+			nil, "jump"},
+		{"any state skips the current one", "hurt",
+			[]AnimTransition{{From: "*", To: "hurt", When: when("hurt")}, {From: "hurt", To: "idle"}},
+			[]string{"hurt"}, "idle"},
+		{"specific added first wins", "run",
+			[]AnimTransition{{From: "run", To: "jump", When: when("jump")}, {From: "*", To: "hurt", When: when("hurt")}},
+			[]string{"jump", "hurt"}, "jump"},
+		{"any added first wins", "run",
+			[]AnimTransition{{From: "*", To: "hurt", When: when("hurt")}, {From: "run", To: "jump", When: when("jump")}},
+			[]string{"jump", "hurt"}, "hurt"},
+		{"later one when the first fails", "run",
+			[]AnimTransition{{From: "*", To: "hurt", When: when("hurt")}, {From: "run", To: "jump", When: when("jump")}},
+			[]string{"jump"}, "jump"},
+		{"one transition per update", "idle",
+			[]AnimTransition{{From: "idle", To: "run"}, {From: "run", To: "jump"}},
+			nil, "run"},
+	}
+
+	for _, test := range tests {
+		s, _ := testSprite(1)
+		m := NewAnimStateMachine(s, test.from)
+		for _, state := range []string{"idle", "run", "jump", "hurt"} {
+			s.AddClip(NewClip(state+"-clip", []int{0}, 10, PlayLoop))
+			m.AddState(state, state+"-clip")
+		}
+		for _, tr := range test.transitions {
+			m.AddTransition(tr)
+		}
+		for _, param := range test.params {
+			m.Params.SetBool(param, true)
+		}
+
+		if err := m.Update(); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if m.State() != test.want || s.Clip() != test.want+"-clip" {
+			t.Errorf("%s: state %s playing %s, want %s", test.name, m.State(), s.Clip(), test.want)
+		}
+	}
+}
+
+func TestAnimStateMachineUnknownState(t *testing.T) {
+	s, _ := testSprite(1)
+	s.AddClip(NewClip("idle", []int{0}, 10, PlayLoop))
+	m := NewAnimStateMachine(s, "idle")
+	m.AddState("idle", "idle")
+	m.AddTransition(AnimTransition{From: "idle", To: "swim"})
+
+	if err := m.Update(); err == nil {
+		t.Errorf("moved to %s, a state that wasn't added, without an error", m.State())
+	}
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/sdl_image"
+	"io/fs"
+)
+
+// LoadTexture loads any image SDL_image understands into a texture for
+// renderer. The caller owns the texture and must Destroy() it.
+func LoadTexture(filepath string, renderer *sdl.Renderer) (*sdl.Texture, error) {
+	texture := img.LoadTexture(renderer, filepath)
+	if texture == nil {
+		return nil, sdlError("IMG_LoadTexture")
+	}
+	return texture, nil
+}
+
+// LoadTextureFS is LoadTexture for files inside an fs.FS, like an embed.FS.
+func LoadTextureFS(fsys fs.FS, name string, renderer *sdl.Renderer) (*sdl.Texture, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New(fmt.Sprintf("Failed to load texture: %s is empty", name))
+	}
+
+	// LoadTextureRW closes the RWops when done
+	rw, free, err := rwFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	defer free()
+
+	texture := img.LoadTextureRW(renderer, rw, true)
+	if texture == nil {
+		return nil, sdlError("IMG_LoadTexture_RW")
+	}
+	return texture, nil
+}
+
+// RenderTexture draws the whole texture scaled into the w x h rect at x, y.
+func RenderTexture(t *sdl.Texture, r *sdl.Renderer, x, y, w, h int) error {
+	rect := sdl.Rect{
+		X: int32(x),
+		Y: int32(y),
+		W: int32(w),
+		H: int32(h),
+	}
+	return Copy(r, t, nil, &rect)
+}
+
+// RenderOriginalTexture draws the texture at x, y using its own size.
+func RenderOriginalTexture(t *sdl.Texture, r *sdl.Renderer, x, y int) error {
+	w, h, err := QueryTexture(t)
+	if err != nil {
+		return err
+	}
+	return RenderTexture(t, r, x, y, w, h)
+}
@@ -0,0 +1,367 @@
+package tilemap
+
+import (
+	"math"
+)
+
+// Collision against the tiles of a layer. A tile's custom properties, set in
+// the tileset, say what it is:
+//
+//	solid        bool  blocks from every side
+//	oneway       bool  a platform, only its top blocks and only from above
+//	slope_left   int   a slope. The height of its floor in pixels, from the
+//	slope_right  int   bottom of the tile, at its left and right edges.
+//
+// Everything else is empty. Horizontally flipped slopes lean the other way.
+
+type tileKind int
+
+const (
+	tileEmpty tileKind = iota
+	tileSolid
+	tileOneWay
+	tileSlope
+)
+
+type tileShape struct {
+	kind tileKind
+
+	// Slopes only
+	left, right float64
+}
+
+// Body is a box that a Collider moves through the map.
+type Body struct {
+	// Top left corner and size in world pixels
+	X, Y          float64
+	Width, Height float64
+
+	// Pixels per second. Running into something zeroes the velocity going
+	// into it.
+	VX, VY float64
+
+	// Fall through one-way platforms, e.g. while down is held
+	DropThrough bool
+
+	// What the last Move ran into
+	OnFloor, OnCeiling, OnWall bool
+	Contacts                   []Contact
+}
+
+// Contact is a tile a body ran into or stood on.
+type Contact struct {
+	TileX, TileY int
+	GID          uint32
+
+	// Normal of the surface touched, pointing out of the tile: 0,-1 for a
+	// floor, 0,1 for a ceiling, 1,0 and -1,0 for walls. Slopes lean.
+	NormalX, NormalY float64
+}
+
+func (c Contact) Floor() bool {
+	return c.NormalY < 0
+}
+
+func (c Contact) Ceiling() bool {
+	return c.NormalY > 0
+}
+
+func (c Contact) Wall() bool {
+	return c.NormalY == 0
+}
+
+// Collider resolves bodies against the tiles of one layer of a map.
+type Collider struct {
+	Map   *Map
+	Layer *TileLayer
+
+	// Longest distance moved in one go. Moves are split in steps no longer
+	// than this so fast bodies can't skip over a tile. A quarter tile by
+	// default.
+	MaxStep float64
+
+	// How far a body on the floor walks up (and sticks down) slopes per
+	// step. Anything steeper is a wall. A quarter tile by default, which
+	// climbs 45 degree slopes.
+	StepHeight float64
+
+	shapes map[uint32]tileShape
+}
+
+func NewCollider(m *Map, layer *TileLayer) *Collider {
+	size := math.Min(float64(m.TileWidth), float64(m.TileHeight))
+	return &Collider{
+		Map:        m,
+		Layer:      layer,
+		MaxStep:    size / 4,
+		StepHeight: float64(m.TileHeight) / 4,
+		shapes:     map[uint32]tileShape{},
+	}
+}
+
+// Move moves b by its velocity for dt seconds. It goes along x then y, in
+// small steps, stopping at walls, floors and ceilings and following slopes.
+func (c *Collider) Move(b *Body, dt float64) {
+	grounded := b.OnFloor
+	b.OnFloor, b.OnCeiling, b.OnWall = false, false, false
+	b.Contacts = b.Contacts[:0]
+
+	dx, dy := b.VX*dt, b.VY*dt
+	steps := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy)) / c.MaxStep))
+	if steps < 1 {
+		steps = 1
+	}
+	dx /= float64(steps)
+	dy /= float64(steps)
+
+	for i := 0; i < steps; i++ {
+		if dx != 0 && c.moveX(b, dx) {
+			dx = 0
+		}
+		if c.moveY(b, dy, grounded || b.OnFloor) {
+			dy = 0
+		}
+	}
+
+	if b.OnWall {
+		b.VX = 0
+	}
+	if b.OnFloor && b.VY > 0 || b.OnCeiling && b.VY < 0 {
+		b.VY = 0
+	}
+}
+
+// moveX moves b sideways and pushes it out of walls. True when it hit one.
+func (c *Collider) moveX(b *Body, dx float64) bool {
+	b.X += dx
+
+	x0, x1, y0, y1 := c.cells(b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+	// Nearest column first
+	first, last, dir := x0, x1, 1
+	if dx < 0 {
+		first, last, dir = x1, x0, -1
+	}
+
+	hit := false
+	for tx := first; tx != last+dir; tx += dir {
+		for ty := y0; ty <= y1; ty++ {
+			gid, shape := c.tile(tx, ty)
+			left, _, right, bottom := c.cellRect(tx, ty)
+			if b.X >= right || b.X+b.Width <= left {
+				// Already pushed clear of this column
+				continue
+			}
+
+			switch shape.kind {
+			case tileSolid:
+			case tileSlope:
+				surface := bottom - shape.height(b.X-left, b.X+b.Width-left, right-left)
+				if b.Y+b.Height-surface <= c.StepHeight {
+					// Walking up it, moveY lifts the body on top
+					continue
+				}
+			default:
+				continue
+			}
+
+			normal := 1.0
+			if dx > 0 {
+				b.X = left - b.Width
+				normal = -1
+			} else {
+				b.X = right
+			}
+			b.OnWall = true
+			b.addContact(Contact{TileX: tx, TileY: ty, GID: gid, NormalX: normal})
+			hit = true
+		}
+	}
+	return hit
+}
+
+// moveY moves b up or down. Going up it stops under ceilings. Otherwise it
+// lands on the highest floor under the body, looking a step further down
+// when grounded so a body walking down a slope stays on it. True when it hit
+// something.
+func (c *Collider) moveY(b *Body, dy float64, grounded bool) bool {
+	prevTop, prevBottom := b.Y, b.Y+b.Height
+	b.Y += dy
+
+	if dy < 0 {
+		return c.ceiling(b, prevTop)
+	}
+
+	reach := 0.0
+	if grounded {
+		reach = c.StepHeight
+	}
+	bottom := b.Y + b.Height
+	// A pixel more to find the floor a body is standing right on
+	x0, x1, y0, y1 := c.cells(b.X, b.Y, b.X+b.Width, bottom+reach+1)
+
+	found := false
+	var floor float64
+	var contact Contact
+	for ty := y0; ty <= y1; ty++ {
+		for tx := x0; tx <= x1; tx++ {
+			gid, shape := c.tile(tx, ty)
+			left, top, right, cellBottom := c.cellRect(tx, ty)
+
+			surface := top
+			normalX, normalY := 0.0, -1.0
+			switch shape.kind {
+			case tileSolid:
+			case tileOneWay:
+				if b.DropThrough || prevBottom > top {
+					continue
+				}
+			case tileSlope:
+				surface = cellBottom - shape.height(b.X-left, b.X+b.Width-left, right-left)
+				normalX, normalY = shape.normal(right - left)
+			default:
+				continue
+			}
+
+			if surface > bottom+reach || found && surface >= floor {
+				continue
+			}
+			found = true
+			floor = surface
+			contact = Contact{TileX: tx, TileY: ty, GID: gid, NormalX: normalX, NormalY: normalY}
+		}
+	}
+
+	if !found {
+		return false
+	}
+	b.Y = floor - b.Height
+	b.OnFloor = true
+	b.addContact(contact)
+	return true
+}
+
+// ceiling pushes b, going up, back under the solid tiles and slopes it
+// bumped into.
+func (c *Collider) ceiling(b *Body, prevTop float64) bool {
+	x0, x1, y0, y1 := c.cells(b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+
+	// Nearest row first
+	for ty := y1; ty >= y0; ty-- {
+		for tx := x0; tx <= x1; tx++ {
+			gid, shape := c.tile(tx, ty)
+			_, _, _, bottom := c.cellRect(tx, ty)
+
+			switch shape.kind {
+			case tileSolid:
+			case tileSlope:
+				// Only the underside of a slope is a ceiling, not the slope
+				// a body jumps off
+				if prevTop < bottom {
+					continue
+				}
+			default:
+				continue
+			}
+			if b.Y >= bottom {
+				continue
+			}
+
+			b.Y = bottom
+			b.OnCeiling = true
+			b.addContact(Contact{TileX: tx, TileY: ty, GID: gid, NormalY: 1})
+			return true
+		}
+	}
+	return false
+}
+
+// cells is the range of layer cells, inclusive, that the box overlaps.
+func (c *Collider) cells(left, top, right, bottom float64) (int, int, int, int) {
+	tw, th := float64(c.Map.TileWidth), float64(c.Map.TileHeight)
+	ox, oy := float64(c.Layer.OffsetX), float64(c.Layer.OffsetY)
+
+	x0 := clamp(int(math.Floor((left-ox)/tw)), 0, c.Layer.Width)
+	x1 := clamp(int(math.Ceil((right-ox)/tw))-1, -1, c.Layer.Width-1)
+	y0 := clamp(int(math.Floor((top-oy)/th)), 0, c.Layer.Height)
+	y1 := clamp(int(math.Ceil((bottom-oy)/th))-1, -1, c.Layer.Height-1)
+	return x0, x1, y0, y1
+}
+
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
+
+// cellRect is the left, top, right and bottom of a cell in world pixels.
+func (c *Collider) cellRect(tx, ty int) (float64, float64, float64, float64) {
+	tw, th := float64(c.Map.TileWidth), float64(c.Map.TileHeight)
+	left := float64(c.Layer.OffsetX) + float64(tx)*tw
+	top := float64(c.Layer.OffsetY) + float64(ty)*th
+	return left, top, left + tw, top + th
+}
+
+// tile is the GID and shape of a cell.
+func (c *Collider) tile(tx, ty int) (uint32, tileShape) {
+	gid := c.Layer.Tile(tx, ty)
+	if gid == 0 {
+		return 0, tileShape{}
+	}
+
+	// Slopes care about horizontal flips, keep that bit in the key
+	key := c.Layer.GIDs[ty*c.Layer.Width+tx] &^ (flippedVertically | flippedDiagonally)
+	shape, ok := c.shapes[key]
+	if !ok {
+		shape = c.shapeOf(key)
+		c.shapes[key] = shape
+	}
+	return gid, shape
+}
+
+func (c *Collider) shapeOf(gid uint32) tileShape {
+	p := c.Map.TileProperties(gid)
+	switch {
+	case p.Bool("solid"):
+		return tileShape{kind: tileSolid}
+	case p.Bool("oneway"):
+		return tileShape{kind: tileOneWay}
+	case p["slope_left"] != "" || p["slope_right"] != "":
+		s := tileShape{kind: tileSlope, left: p.Float("slope_left"), right: p.Float("slope_right")}
+		if gid&flippedHorizontally != 0 {
+			s.left, s.right = s.right, s.left
+		}
+		return s
+	}
+	return tileShape{}
+}
+
+// height is the highest the floor of a slope gets between x0 and x1, in
+// pixels from the tile's left edge, for a tile width pixels wide.
+func (s tileShape) height(x0, x1, width float64) float64 {
+	at := func(x float64) float64 {
+		x = math.Max(0, math.Min(width, x))
+		return s.left + (s.right-s.left)*x/width
+	}
+	return math.Max(at(x0), at(x1))
+}
+
+// normal of a slope's floor, pointing up out of it.
+func (s tileShape) normal(width float64) (float64, float64) {
+	nx, ny := -(s.right - s.left), -width
+	length := math.Hypot(nx, ny)
+	return nx / length, ny / length
+}
+
+// addContact records a contact once, however many steps touched it.
+func (b *Body) addContact(contact Contact) {
+	for _, seen := range b.Contacts {
+		if seen == contact {
+			return
+		}
+	}
+	b.Contacts = append(b.Contacts, contact)
+}
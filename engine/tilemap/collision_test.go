@@ -0,0 +1,165 @@
+package tilemap
+
+import (
+	"math"
+	"testing"
+)
+
+// testMap is a map of 16x16 tiles drawn with characters:
+//
+//	#  solid
+//	-  one-way platform
+//	/  slope going up to the right
+//	\  slope going down to the right
+func testMap(rows ...string) (*Map, *TileLayer) {
+	gids := map[rune]uint32{'#': 1, '-': 2, '/': 3, '\\': 4}
+	layer := &TileLayer{Name: "ground", Width: len(rows[0]), Height: len(rows), Visible: true}
+	for _, row := range rows {
+		for _, c := range row {
+			layer.GIDs = append(layer.GIDs, gids[c])
+		}
+	}
+
+	tileset := &Tileset{
+		FirstGID:  1,
+		Name:      "test",
+		TileWidth: 16, TileHeight: 16,
+		TileCount: 4, Columns: 4,
+		TileProperties: map[int]Properties{
+			0: {"solid": "true"},
+			1: {"oneway": "true"},
+			2: {"slope_left": "0", "slope_right": "16"},
+			3: {"slope_left": "16", "slope_right": "0"},
+		},
+	}
+	m := &Map{
+		Width: layer.Width, Height: layer.Height,
+		TileWidth: 16, TileHeight: 16,
+		Tilesets: []*Tileset{tileset},
+		Layers:   []*TileLayer{layer},
+	}
+	return m, layer
+}
+
+func TestColliderMove(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		body Body
+		dt   float64
+
+		x, y                       float64
+		vx, vy                     float64
+		onFloor, onCeiling, onWall bool
+	}{
+		{
+			name: "lands on the floor",
+			rows: []string{"........", "........", "........", "........", "........", "########"},
+			body: Body{X: 20, Y: 60, Width: 12, Height: 12, VY: 300},
+			dt:   0.1,
+			x:    20, y: 68, onFloor: true,
+		},
+		{
+			name: "stopped by a wall",
+			rows: []string{"........", "........", ".....#..", "........"},
+			body: Body{X: 60, Y: 36, Width: 8, Height: 8, VX: 300},
+			dt:   0.1,
+			x:    72, y: 36, onWall: true,
+		},
+		{
+			name: "bumps its head",
+			rows: []string{"........", ".#......", "........", "........"},
+			body: Body{X: 18, Y: 40, Width: 8, Height: 8, VY: -300},
+			dt:   0.1,
+			x:    18, y: 32, onCeiling: true,
+		},
+		{
+			name: "lands on a one-way platform",
+			rows: []string{"........", "........", "........", "--------", "........"},
+			body: Body{X: 20, Y: 30, Width: 8, Height: 8, VY: 200},
+			dt:   0.1,
+			x:    20, y: 40, onFloor: true,
+		},
+		{
+			name: "jumps up through a one-way platform",
+			rows: []string{"........", "........", "........", "--------", "........", "........"},
+			body: Body{X: 20, Y: 70, Width: 8, Height: 8, VY: -200},
+			dt:   0.1,
+			x:    20, y: 50, vy: -200,
+		},
+		{
+			name: "drops through a one-way platform",
+			rows: []string{"........", "........", "........", "--------", "........", "........"},
+			body: Body{X: 20, Y: 30, Width: 8, Height: 8, VY: 200, DropThrough: true},
+			dt:   0.1,
+			x:    20, y: 50, vy: 200,
+		},
+		{
+			name: "walks up a slope",
+			rows: []string{"........", "........", "........", "........", ".../....", "########"},
+			body: Body{X: 36, Y: 72, Width: 8, Height: 8, VX: 100, OnFloor: true},
+			dt:   0.1,
+			x:    46, y: 66, vx: 100, onFloor: true,
+		},
+		{
+			name: "walks down a slope",
+			rows: []string{"........", "........", "........", "........", "...\\....", "########"},
+			body: Body{X: 48, Y: 56, Width: 8, Height: 8, VX: 100, OnFloor: true},
+			dt:   0.1,
+			x:    58, y: 66, vx: 100, onFloor: true,
+		},
+		{
+			name: "a slope's high side is a wall",
+			rows: []string{"........", "........", "........", "........", "...\\....", "########"},
+			body: Body{X: 36, Y: 72, Width: 8, Height: 8, VX: 100, OnFloor: true},
+			dt:   0.1,
+			x:    40, y: 72, onFloor: true, onWall: true,
+		},
+		{
+			name: "doesn't tunnel through the floor",
+			rows: []string{"........", "........", "........", "########", "........", "........"},
+			body: Body{X: 20, Y: 0, Width: 8, Height: 8, VY: 2000},
+			dt:   0.1,
+			x:    20, y: 40, onFloor: true,
+		},
+	}
+
+	for _, test := range tests {
+		m, layer := testMap(test.rows...)
+		c := NewCollider(m, layer)
+		b := test.body
+		c.Move(&b, test.dt)
+
+		if !near(b.X, test.x) || !near(b.Y, test.y) || !near(b.VX, test.vx) || !near(b.VY, test.vy) {
+			t.Errorf("%s: at %v,%v going %v,%v, want %v,%v going %v,%v",
+				test.name, b.X, b.Y, b.VX, b.VY, test.x, test.y, test.vx, test.vy)
+		}
+		if b.OnFloor != test.onFloor || b.OnCeiling != test.onCeiling || b.OnWall != test.onWall {
+			t.Errorf("%s: floor %v, ceiling %v, wall %v, want %v, %v, %v",
+				test.name, b.OnFloor, b.OnCeiling, b.OnWall, test.onFloor, test.onCeiling, test.onWall)
+		}
+	}
+}
+
+func TestColliderContacts(t *testing.T) {
+	m, layer := testMap("........", "........", "........", "........", ".../....", "########")
+	c := NewCollider(m, layer)
+	b := &Body{X: 36, Y: 72, Width: 8, Height: 8, VX: 100, OnFloor: true}
+	c.Move(b, 0.1)
+
+	if len(b.Contacts) == 0 {
+		t.Fatal("no contacts")
+	}
+	last := b.Contacts[len(b.Contacts)-1]
+	if last.TileX != 3 || last.TileY != 4 || last.GID != 3 || !last.Floor() {
+		t.Errorf("standing on %+v, want the slope at 3,4", last)
+	}
+	// Going up to the right, the normal leans left
+	if last.NormalX >= 0 || !near(math.Hypot(last.NormalX, last.NormalY), 1) {
+		t.Errorf("slope normal %v,%v", last.NormalX, last.NormalY)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
@@ -0,0 +1,68 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodeCSV reads layer data saved with encoding="csv".
+func decodeCSV(data string, n int) ([]uint32, error) {
+	fields := strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+
+	gids := make([]uint32, 0, n)
+	for _, field := range fields {
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		gids = append(gids, uint32(gid))
+	}
+	return checkLength(gids, n)
+}
+
+// decodeBase64 reads layer data saved with encoding="base64": little endian
+// uint32 GIDs, optionally zlib or gzip compressed.
+func decodeBase64(data, compression string, n int) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("tilemap: %s compressed layers aren't supported", compression))
+	}
+
+	gids := make([]uint32, n)
+	if err := binary.Read(r, binary.LittleEndian, gids); err != nil {
+		return nil, errors.New(fmt.Sprintf("tilemap: bad layer data: %s", err))
+	}
+	return gids, nil
+}
+
+func checkLength(gids []uint32, n int) ([]uint32, error) {
+	if len(gids) != n {
+		return nil, errors.New(fmt.Sprintf("tilemap: layer has %d tiles, expected %d", len(gids), n))
+	}
+	return gids, nil
+}
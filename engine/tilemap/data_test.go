@@ -0,0 +1,114 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// Top row plain, bottom row flipped: horizontally, vertically and diagonally.
+var testGIDs = []uint32{
+	1, 2, 0,
+	3 | flippedHorizontally, 4 | flippedVertically, 1 | flippedDiagonally,
+}
+
+// encodeGIDs is layer data the way Tiled saves it with encoding="base64".
+func encodeGIDs(t *testing.T, compression string, gids []uint32) string {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var closer io.Closer
+	switch compression {
+	case "zlib":
+		z := zlib.NewWriter(&buf)
+		w, closer = z, z
+	case "gzip":
+		z := gzip.NewWriter(&buf)
+		w, closer = z, z
+	}
+	if err := binary.Write(w, binary.LittleEndian, gids); err != nil {
+		t.Fatal(err)
+	}
+	if closer != nil {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecodeCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		n    int
+		want []uint32
+		err  bool
+	}{
+		{"one line", "1,2,0,2147483651,1073741828,536870913", 6, testGIDs, false},
+		{"as saved by Tiled", "\n1,2,0,\n2147483651,1073741828,536870913\n", 6, testGIDs, false},
+		{"spaces", " 1, 2, 0,\r\n\t2147483651, 1073741828, 536870913 ", 6, testGIDs, false},
+		{"too short", "1,2,0", 6, nil, true},
+		{"too long", "1,2,0,3,4,5,6", 6, nil, true},
+		{"not a number", "1,2,x,3,4,5", 6, nil, true},
+		{"negative", "1,2,-1,3,4,5", 6, nil, true},
+	}
+
+	for _, test := range tests {
+		gids, err := decodeCSV(test.data, test.n)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", test.name, gids)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(gids, test.want) {
+			t.Errorf("%s: got %v, expected %v", test.name, gids, test.want)
+		}
+	}
+}
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		compression string
+		n           int
+		err         bool
+	}{
+		{"uncompressed", encodeGIDs(t, "", testGIDs), "", 6, false},
+		{"zlib", encodeGIDs(t, "zlib", testGIDs), "zlib", 6, false},
+		{"gzip", encodeGIDs(t, "gzip", testGIDs), "gzip", 6, false},
+		{"surrounding whitespace", "\n   " + encodeGIDs(t, "zlib", testGIDs) + "\n  ", "zlib", 6, false},
+		{"zstd", encodeGIDs(t, "", testGIDs), "zstd", 6, true},
+		{"not base64", "!!!!", "", 6, true},
+		{"too short", encodeGIDs(t, "", testGIDs[:4]), "", 6, true},
+		{"not zlib", encodeGIDs(t, "", testGIDs), "zlib", 6, true},
+		{"not gzip", encodeGIDs(t, "zlib", testGIDs), "gzip", 6, true},
+	}
+
+	for _, test := range tests {
+		gids, err := decodeBase64(test.data, test.compression, test.n)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", test.name, gids)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(gids, testGIDs) {
+			t.Errorf("%s: got %v, expected %v", test.name, gids, testGIDs)
+		}
+	}
+}
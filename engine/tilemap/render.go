@@ -0,0 +1,103 @@
+package tilemap
+
+import (
+	"github.com/paydro/gamedev/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Draw renders every visible tile layer, bottom to top.
+func (m *Map) Draw(r *sdl.Renderer) error {
+	for _, l := range m.Layers {
+		if !l.Visible {
+			continue
+		}
+		if err := m.DrawLayer(r, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrawLayer renders the tiles of l that are in view: inside the camera set on
+// r, or the viewport when there is no camera.
+func (m *Map) DrawLayer(r *sdl.Renderer, l *TileLayer) error {
+	var view sdl.Rect
+	if c := engine.CameraFor(r); c != nil {
+		view = c.Visible()
+	} else {
+		r.GetViewport(&view)
+	}
+
+	// Tiles bigger than the grid stick out above their cell, look one extra
+	// row down for them
+	x0, x1 := visibleRange(int(view.X)-l.OffsetX, int(view.W), m.TileWidth, l.Width)
+	y0, y1 := visibleRange(int(view.Y)-l.OffsetY, int(view.H)+m.TileHeight, m.TileHeight, l.Height)
+
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			gid := l.GIDs[y*l.Width+x]
+			if gid&^flipMask == 0 {
+				continue
+			}
+			if err := m.drawTile(r, gid, l.OffsetX+x*m.TileWidth, l.OffsetY+(y+1)*m.TileHeight); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// visibleRange is the first and past the last cell, of count cells of size
+// pixels, that overlap pos .. pos+length.
+func visibleRange(pos, length, size, count int) (int, int) {
+	first := pos / size
+	last := (pos+length)/size + 1
+	if first < 0 {
+		first = 0
+	}
+	if last > count {
+		last = count
+	}
+	return first, last
+}
+
+// drawTile draws gid with its bottom left corner at x, bottom, the way Tiled
+// lines up tiles of any size.
+func (m *Map) drawTile(r *sdl.Renderer, gid uint32, x, bottom int) error {
+	ts, ok := m.Tileset(gid)
+	if !ok {
+		return nil
+	}
+
+	src := ts.Sheet.Rect(int(gid&^flipMask - ts.FirstGID))
+	dst := sdl.Rect{
+		X: int32(x),
+		Y: int32(bottom - ts.TileHeight),
+		W: int32(ts.TileWidth),
+		H: int32(ts.TileHeight),
+	}
+
+	if gid&flipMask == 0 {
+		return engine.Copy(r, ts.Sheet.Texture, &src, &dst)
+	}
+
+	// Tiled flips diagonally first, then horizontally and vertically. A
+	// diagonal flip is a 90 degree turn plus a vertical flip, and turning
+	// swaps which way the other flips go.
+	h := gid&flippedHorizontally != 0
+	v := gid&flippedVertically != 0
+	angle := 0.0
+	if gid&flippedDiagonally != 0 {
+		angle = 90
+		h, v = v, !h
+	}
+
+	var flip sdl.RendererFlip = sdl.FLIP_NONE
+	if h {
+		flip |= sdl.FLIP_HORIZONTAL
+	}
+	if v {
+		flip |= sdl.FLIP_VERTICAL
+	}
+	return engine.CopyEx(r, ts.Sheet.Texture, &src, &dst, angle, nil, flip)
+}
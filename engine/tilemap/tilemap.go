@@ -0,0 +1,352 @@
+// Package tilemap loads maps made with the Tiled editor
+// (http://www.mapeditor.org) and draws them.
+//
+// Maps can be saved as TMX (XML) or JSON (.tmj/.json), with tilesets inline
+// or in their own TSX/TSJ files. Orthogonal, finite maps only. Tile layers,
+// groups of them, object layers and custom properties are all loaded:
+//
+//	m, err := tilemap.Load(w.Assets, "level1.tmx")
+//	if err != nil { ... }
+//	defer m.Release(w.Assets)
+//
+//	x, y, _ := m.SpawnPoint("yoshi")
+//	...
+//	m.Draw(r) // only the tiles in view of the renderer's camera
+//
+// A Collider moves boxes through a tile layer, stopping them at the tiles
+// marked solid, oneway or as slopes in the tileset (see collision.go).
+package tilemap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paydro/gamedev/engine"
+	"github.com/veandco/go-sdl2/sdl"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tiled stores tile flips in the top bits of a GID.
+const (
+	flippedHorizontally = 0x80000000
+	flippedVertically   = 0x40000000
+	flippedDiagonally   = 0x20000000
+	flipMask            = flippedHorizontally | flippedVertically | flippedDiagonally
+)
+
+type Map struct {
+	// Size in tiles
+	Width, Height int
+
+	// Size of a grid cell in pixels
+	TileWidth, TileHeight int
+
+	Properties Properties
+
+	// Ordered by FirstGID
+	Tilesets []*Tileset
+
+	// Tile layers in draw order, groups flattened
+	Layers []*TileLayer
+
+	ObjectGroups []*ObjectGroup
+}
+
+type Tileset struct {
+	// GID of the first tile, the others follow
+	FirstGID uint32
+
+	Name                  string
+	TileWidth, TileHeight int
+	Spacing, Margin       int
+	Columns, TileCount    int
+
+	// Image path as resolved through the assets, and the cut up texture
+	Image string
+	Sheet *engine.SpriteSheet
+
+	// Custom properties of single tiles, by local tile id
+	TileProperties map[int]Properties
+}
+
+type TileLayer struct {
+	Name             string
+	Width, Height    int
+	Visible          bool
+	OffsetX, OffsetY int
+	Properties       Properties
+
+	// One GID per cell, row by row. 0 is an empty cell. The top bits are
+	// Tiled's flip flags, use Tile() to get the plain GID.
+	GIDs []uint32
+}
+
+// ObjectGroup is an object layer. Use it for spawn points, triggers and
+// anything else that isn't tiles.
+type ObjectGroup struct {
+	Name       string
+	Visible    bool
+	Properties Properties
+	Objects    []*Object
+}
+
+type Object struct {
+	ID   int
+	Name string
+
+	// The object's class (called "type" before Tiled 1.9)
+	Type string
+
+	// Top left corner and size in pixels. Tiled puts tile objects at their
+	// bottom left, they are moved up here so every object is the same.
+	X, Y          float64
+	Width, Height float64
+
+	// Tile objects only
+	GID uint32
+
+	Properties Properties
+}
+
+// Properties are Tiled's custom properties. Values are kept as written in
+// the file and converted on access.
+type Properties map[string]string
+
+func (p Properties) String(name string) string {
+	return p[name]
+}
+
+func (p Properties) Bool(name string) bool {
+	return p[name] == "true"
+}
+
+func (p Properties) Int(name string) int {
+	n, _ := strconv.Atoi(p[name])
+	return n
+}
+
+func (p Properties) Float(name string) float64 {
+	f, _ := strconv.ParseFloat(p[name], 64)
+	return f
+}
+
+// Load reads a TMX or JSON map and everything it uses through assets. Paths
+// in the map are relative to it.
+func Load(assets *engine.Assets, mapPath string) (*Map, error) {
+	data, err := assets.ReadFile(mapPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Map
+	var refs []tilesetRef
+	switch strings.ToLower(path.Ext(mapPath)) {
+	case ".tmx", ".xml":
+		m, refs, err = parseTMX(data)
+	case ".tmj", ".json":
+		m, refs, err = parseTMJ(data)
+	default:
+		err = errors.New(fmt.Sprintf("tilemap: don't know how to load %s", mapPath))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Dir(mapPath)
+	for _, ref := range refs {
+		ts := ref.tileset
+		if ref.source != "" {
+			ts, err = loadTileset(assets, path.Join(dir, ref.source))
+			if err != nil {
+				m.Release(assets)
+				return nil, err
+			}
+			ts.FirstGID = ref.firstGID
+		} else {
+			ts.Image = path.Join(dir, ts.Image)
+		}
+
+		if err := ts.loadTexture(assets); err != nil {
+			m.Release(assets)
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	sort.Slice(m.Tilesets, func(i, j int) bool {
+		return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID
+	})
+
+	return m, nil
+}
+
+// tilesetRef is a tileset as listed in the map: either inline or the path of
+// a TSX/TSJ file.
+type tilesetRef struct {
+	firstGID uint32
+	source   string
+	tileset  *Tileset
+}
+
+func loadTileset(assets *engine.Assets, tilesetPath string) (*Tileset, error) {
+	data, err := assets.ReadFile(tilesetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var ts *Tileset
+	switch strings.ToLower(path.Ext(tilesetPath)) {
+	case ".tsx", ".xml":
+		ts, err = parseTSX(data)
+	case ".tsj", ".json":
+		ts, err = parseTSJ(data)
+	default:
+		err = errors.New(fmt.Sprintf("tilemap: don't know how to load tileset %s", tilesetPath))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ts.Image = path.Join(path.Dir(tilesetPath), ts.Image)
+	return ts, nil
+}
+
+func (ts *Tileset) loadTexture(assets *engine.Assets) error {
+	if ts.Image == "" {
+		return errors.New(fmt.Sprintf("tilemap: tileset %s has no image, image collections aren't supported", ts.Name))
+	}
+
+	texture, err := assets.Texture(ts.Image)
+	if err != nil {
+		return err
+	}
+
+	if ts.Columns == 0 {
+		w, _, err := engine.QueryTexture(texture)
+		if err != nil {
+			assets.Release(texture)
+			return err
+		}
+		ts.Columns = (w - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+
+	if ts.Columns < 1 {
+		assets.Release(texture)
+		return errors.New(fmt.Sprintf("tilemap: tileset %s has no columns of %dx%d tiles", ts.Name, ts.TileWidth, ts.TileHeight))
+	}
+	rows := (ts.TileCount + ts.Columns - 1) / ts.Columns
+	sheet, err := engine.NewSpriteSheetGrid(texture, ts.TileWidth, ts.TileHeight, ts.Columns, rows)
+	if err != nil {
+		assets.Release(texture)
+		return errors.New(fmt.Sprintf("tilemap: tileset %s: %v", ts.Name, err))
+	}
+	ts.Sheet = sheet
+	ts.Sheet.Margin = ts.Margin
+	ts.Sheet.Spacing = ts.Spacing
+	return nil
+}
+
+// Release gives the tileset textures back to assets.
+func (m *Map) Release(assets *engine.Assets) {
+	for _, ts := range m.Tilesets {
+		if ts.Sheet != nil {
+			assets.Release(ts.Sheet.Texture)
+			ts.Sheet = nil
+		}
+	}
+}
+
+// Tile returns the plain GID (no flip flags) of the cell at x, y. 0 is empty,
+// and so is anything outside the layer.
+func (l *TileLayer) Tile(x, y int) uint32 {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.GIDs[y*l.Width+x] &^ flipMask
+}
+
+// Layer finds a tile layer by name.
+func (m *Map) Layer(name string) (*TileLayer, bool) {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// ObjectGroup finds an object layer by name.
+func (m *Map) ObjectGroup(name string) (*ObjectGroup, bool) {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// Object finds the first object called name in any object layer.
+func (m *Map) Object(name string) (*Object, bool) {
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			if o.Name == name {
+				return o, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ObjectsOfType lists the objects of a class, e.g. every "enemy" spawn.
+func (m *Map) ObjectsOfType(typ string) []*Object {
+	var objects []*Object
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			if o.Type == typ {
+				objects = append(objects, o)
+			}
+		}
+	}
+	return objects
+}
+
+// SpawnPoint is where the object called name is, in world pixels. Put a
+// point (or any object) named after an entity in an object layer to place it
+// from the editor.
+func (m *Map) SpawnPoint(name string) (float64, float64, bool) {
+	o, ok := m.Object(name)
+	if !ok {
+		return 0, 0, false
+	}
+	return o.X, o.Y, true
+}
+
+// Tileset is the tileset a GID (flip flags are ignored) belongs to.
+func (m *Map) Tileset(gid uint32) (*Tileset, bool) {
+	gid &^= flipMask
+	if gid == 0 {
+		return nil, false
+	}
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		if m.Tilesets[i].FirstGID <= gid {
+			return m.Tilesets[i], true
+		}
+	}
+	return nil, false
+}
+
+// TileProperties are the custom properties set on a tile in its tileset. nil
+// when there are none.
+func (m *Map) TileProperties(gid uint32) Properties {
+	ts, ok := m.Tileset(gid)
+	if !ok {
+		return nil
+	}
+	return ts.TileProperties[int(gid&^flipMask-ts.FirstGID)]
+}
+
+// Bounds is the size of the map in pixels.
+func (m *Map) Bounds() sdl.Rect {
+	return sdl.Rect{W: int32(m.Width * m.TileWidth), H: int32(m.Height * m.TileHeight)}
+}
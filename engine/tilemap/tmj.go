@@ -0,0 +1,219 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Tiled's JSON format (.tmj maps, .tsj tilesets). See
+// http://doc.mapeditor.org/reference/json-map-format/
+
+type tmjMap struct {
+	Orientation string        `json:"orientation"`
+	Infinite    bool          `json:"infinite"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Properties  []tmjProperty `json:"properties"`
+	Tilesets    []tmjTileset  `json:"tilesets"`
+	Layers      []tmjLayer    `json:"layers"`
+}
+
+type tmjProperty struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type tmjTileset struct {
+	FirstGID   uint32        `json:"firstgid"`
+	Source     string        `json:"source"`
+	Name       string        `json:"name"`
+	TileWidth  int           `json:"tilewidth"`
+	TileHeight int           `json:"tileheight"`
+	Spacing    int           `json:"spacing"`
+	Margin     int           `json:"margin"`
+	TileCount  int           `json:"tilecount"`
+	Columns    int           `json:"columns"`
+	Image      string        `json:"image"`
+	Tiles      []tmjTileInfo `json:"tiles"`
+}
+
+type tmjTileInfo struct {
+	ID         int           `json:"id"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Visible     *bool           `json:"visible"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Properties  []tmjProperty   `json:"properties"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Objects     []tmjObject     `json:"objects"`
+	Layers      []tmjLayer      `json:"layers"`
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	GID        uint32        `json:"gid"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+func parseTMJ(data []byte) (*Map, []tilesetRef, error) {
+	var doc tmjMap
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if err := checkMap(doc.Orientation, doc.Infinite); err != nil {
+		return nil, nil, err
+	}
+
+	m := &Map{
+		Width:      doc.Width,
+		Height:     doc.Height,
+		TileWidth:  doc.TileWidth,
+		TileHeight: doc.TileHeight,
+		Properties: tmjProperties(doc.Properties),
+	}
+
+	var refs []tilesetRef
+	for _, t := range doc.Tilesets {
+		ref := tilesetRef{firstGID: t.FirstGID, source: t.Source}
+		if t.Source == "" {
+			ref.tileset = tmjToTileset(t)
+			ref.tileset.FirstGID = t.FirstGID
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := m.addTMJLayers(doc.Layers, 0, 0, true); err != nil {
+		return nil, nil, err
+	}
+	return m, refs, nil
+}
+
+// addTMJLayers flattens layers and groups into the map. Group offsets and
+// visibility apply to everything inside them.
+func (m *Map) addTMJLayers(layers []tmjLayer, offsetX, offsetY float64, visible bool) error {
+	for _, l := range layers {
+		x, y := offsetX+l.OffsetX, offsetY+l.OffsetY
+		shown := visible && (l.Visible == nil || *l.Visible)
+
+		switch l.Type {
+		case "tilelayer":
+			gids, err := tmjLayerData(l)
+			if err != nil {
+				return err
+			}
+			m.Layers = append(m.Layers, &TileLayer{
+				Name:       l.Name,
+				Width:      l.Width,
+				Height:     l.Height,
+				Visible:    shown,
+				OffsetX:    int(x),
+				OffsetY:    int(y),
+				Properties: tmjProperties(l.Properties),
+				GIDs:       gids,
+			})
+
+		case "objectgroup":
+			group := &ObjectGroup{
+				Name:       l.Name,
+				Visible:    shown,
+				Properties: tmjProperties(l.Properties),
+			}
+			for _, o := range l.Objects {
+				typ := o.Type
+				if o.Class != "" {
+					typ = o.Class
+				}
+				group.Objects = append(group.Objects, newObject(o.ID, o.Name, typ,
+					o.X+x, o.Y+y, o.Width, o.Height, o.GID, tmjProperties(o.Properties)))
+			}
+			m.ObjectGroups = append(m.ObjectGroups, group)
+
+		case "group":
+			if err := m.addTMJLayers(l.Layers, x, y, shown); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tmjLayerData(l tmjLayer) ([]uint32, error) {
+	n := l.Width * l.Height
+	switch l.Encoding {
+	case "", "csv":
+		var gids []uint32
+		if err := json.Unmarshal(l.Data, &gids); err != nil {
+			return nil, err
+		}
+		return checkLength(gids, n)
+	case "base64":
+		var s string
+		if err := json.Unmarshal(l.Data, &s); err != nil {
+			return nil, err
+		}
+		return decodeBase64(s, l.Compression, n)
+	}
+	return nil, errors.New(fmt.Sprintf("tilemap: unknown layer encoding %s", l.Encoding))
+}
+
+func parseTSJ(data []byte) (*Tileset, error) {
+	var doc tmjTileset
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return tmjToTileset(doc), nil
+}
+
+func tmjToTileset(t tmjTileset) *Tileset {
+	ts := &Tileset{
+		Name:           t.Name,
+		TileWidth:      t.TileWidth,
+		TileHeight:     t.TileHeight,
+		Spacing:        t.Spacing,
+		Margin:         t.Margin,
+		Columns:        t.Columns,
+		TileCount:      t.TileCount,
+		Image:          t.Image,
+		TileProperties: make(map[int]Properties),
+	}
+	for _, tile := range t.Tiles {
+		if len(tile.Properties) > 0 {
+			ts.TileProperties[tile.ID] = tmjProperties(tile.Properties)
+		}
+	}
+	return ts
+}
+
+// tmjProperties keeps values the way TMX writes them: strings as is,
+// everything else (numbers, bools) as its JSON text.
+func tmjProperties(props []tmjProperty) Properties {
+	p := make(Properties)
+	for _, prop := range props {
+		var s string
+		if err := json.Unmarshal(prop.Value, &s); err == nil {
+			p[prop.Name] = s
+		} else {
+			p[prop.Name] = string(prop.Value)
+		}
+	}
+	return p
+}
@@ -0,0 +1,149 @@
+package tilemap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testTMJ is testTMX saved as JSON.
+func testTMJ(t *testing.T) string {
+	return fmt.Sprintf(`{
+ "type": "map", "version": "1.10", "orientation": "orthogonal", "renderorder": "right-down",
+ "width": 3, "height": 2, "tilewidth": 16, "tileheight": 16, "infinite": false,
+ "properties": [
+  {"name": "background", "type": "string", "value": "10,20,30"},
+  {"name": "gravity", "type": "float", "value": 9.5},
+  {"name": "lives", "type": "int", "value": 3},
+  {"name": "dark", "type": "bool", "value": true},
+  {"name": "intro", "type": "string", "value": "Once upon\na time"}
+ ],
+ "tilesets": [
+  {"firstgid": 1, "name": "ground", "tilewidth": 16, "tileheight": 16, "spacing": 1, "margin": 2,
+   "tilecount": 4, "columns": 2, "image": "ground.png", "imagewidth": 36, "imageheight": 36,
+   "tiles": [
+    {"id": 0, "properties": [{"name": "solid", "type": "bool", "value": true}]},
+    {"id": 3}
+   ]},
+  {"firstgid": 5, "source": "tiles/props.tsj"}
+ ],
+ "layers": [
+  {"id": 1, "type": "tilelayer", "name": "csv", "width": 3, "height": 2, "visible": true,
+   "data": [1, 2, 0, 2147483651, 1073741828, 536870913]},
+  {"id": 2, "type": "tilelayer", "name": "zlib", "width": 3, "height": 2, "visible": false,
+   "encoding": "base64", "compression": "zlib", "data": "%s"},
+  {"id": 3, "type": "group", "name": "background", "offsetx": 10, "offsety": 20, "visible": false,
+   "layers": [
+    {"id": 4, "type": "tilelayer", "name": "gzip", "width": 3, "height": 2, "offsetx": 1, "offsety": 2,
+     "properties": [{"name": "parallax", "type": "float", "value": 0.5}],
+     "encoding": "base64", "compression": "gzip", "data": "%s"},
+    {"id": 5, "type": "group", "name": "nested", "offsetx": 100, "layers": [
+     {"id": 6, "type": "objectgroup", "name": "decor", "objects": [
+      {"id": 1, "name": "cloud", "x": 0, "y": 0}
+     ]}
+    ]}
+   ]},
+  {"id": 7, "type": "tilelayer", "name": "xml", "width": 3, "height": 2, "encoding": "csv",
+   "data": [1, 2, 0, 2147483651, 1073741828, 536870913]},
+  {"id": 8, "type": "imagelayer", "name": "sky", "image": "sky.png"},
+  {"id": 9, "type": "objectgroup", "name": "spawns", "objects": [
+   {"id": 2, "name": "yoshi", "type": "player", "x": 32, "y": 48, "point": true},
+   {"id": 3, "name": "shyguy", "class": "enemy", "x": 64, "y": 48, "width": 16, "height": 16, "gid": 5,
+    "properties": [{"name": "speed", "type": "int", "value": 40}]},
+   {"id": 4, "name": "shyguy", "type": "ignored", "class": "enemy", "x": 80, "y": 48, "width": 16, "height": 16}
+  ]}
+ ]
+}`, encodeGIDs(t, "zlib", testGIDs), encodeGIDs(t, "gzip", testGIDs))
+}
+
+const testTSJ = `{
+ "type": "tileset", "version": "1.10", "name": "props", "tilewidth": 16, "tileheight": 32,
+ "tilecount": 2, "columns": 2, "image": "props.png", "imagewidth": 32, "imageheight": 32,
+ "tiles": [{"id": 1, "properties": [{"name": "oneway", "type": "bool", "value": true}]}]
+}`
+
+// TestParseTMJ loads the same map as TestParseTMX, everything should come
+// out the same.
+func TestParseTMJ(t *testing.T) {
+	m, refs, err := parseTMJ([]byte(testTMJ(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmx, tmxRefs, err := parseTMX([]byte(testTMX(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(m.Properties, tmx.Properties) {
+		t.Errorf("Map properties are %v, expected %v", m.Properties, tmx.Properties)
+	}
+	if m.Properties.Float("gravity") != 9.5 || m.Properties.Int("lives") != 3 || !m.Properties.Bool("dark") {
+		t.Errorf("Typed properties read as %v, %v, %v", m.Properties.Float("gravity"), m.Properties.Int("lives"), m.Properties.Bool("dark"))
+	}
+
+	if len(refs) != 2 {
+		t.Fatalf("Got %d tilesets, expected 2", len(refs))
+	}
+	if !reflect.DeepEqual(refs[0].tileset, tmxRefs[0].tileset) {
+		t.Errorf("Inline tileset is %+v, expected %+v", refs[0].tileset, tmxRefs[0].tileset)
+	}
+	if refs[1].tileset != nil || refs[1].source != "tiles/props.tsj" || refs[1].firstGID != 5 {
+		t.Errorf("Second tileset should be tiles/props.tsj at 5, got %+v", refs[1])
+	}
+
+	// The image layer is skipped
+	if len(m.Layers) != len(tmx.Layers) {
+		t.Fatalf("Got %d layers, expected %d", len(m.Layers), len(tmx.Layers))
+	}
+	for i, l := range m.Layers {
+		if !reflect.DeepEqual(l, tmx.Layers[i]) {
+			t.Errorf("Layer %d is %+v, expected %+v", i, l, tmx.Layers[i])
+		}
+	}
+
+	if len(m.ObjectGroups) != 2 {
+		t.Fatalf("Got %d object groups, expected 2", len(m.ObjectGroups))
+	}
+	decor := m.ObjectGroups[0]
+	if decor.Name != "decor" || decor.Visible {
+		t.Errorf("Group %s visible %v, expected decor hidden by its parent", decor.Name, decor.Visible)
+	}
+	if o := decor.Objects[0]; o.X != 110 || o.Y != 20 {
+		t.Errorf("cloud is at %v,%v, expected both groups' offsets 110,20", o.X, o.Y)
+	}
+	checkSpawns(t, m)
+}
+
+func TestParseTMJErrors(t *testing.T) {
+	layer := func(fields string) string {
+		return `{"orientation": "orthogonal", "width": 2, "height": 1, "layers": [{"type": "tilelayer", "name": "bad", "width": 2, "height": 1, ` + fields + `}]}`
+	}
+	tests := []struct {
+		name string
+		tmj  string
+		err  string
+	}{
+		{"malformed", `{"width": 2, "layers": [`, "unexpected end of JSON"},
+		{"hexagonal", `{"orientation": "hexagonal"}`, "hexagonal maps"},
+		{"infinite", `{"orientation": "orthogonal", "infinite": true}`, "infinite maps"},
+		{"short array", layer(`"data": [1]`), "layer has 1 tiles, expected 2"},
+		{"strings in array", layer(`"data": ["1", "2"]`), "cannot unmarshal string"},
+		{"array as base64", layer(`"encoding": "base64", "data": [1, 2]`), "cannot unmarshal array"},
+		{"short base64", layer(`"encoding": "base64", "data": "` + encodeGIDs(t, "", []uint32{1}) + `"`), "bad layer data"},
+		{"bad compression", layer(`"encoding": "base64", "compression": "zstd", "data": "AAAA"`), "zstd compressed layers"},
+		{"bad encoding", layer(`"encoding": "hex", "data": "0102"`), "unknown layer encoding hex"},
+		{"in a group", `{"layers": [{"type": "group", "layers": [{"type": "tilelayer", "width": 2, "height": 1, "data": [1]}]}]}`, "layer has 1 tiles"},
+	}
+
+	for _, test := range tests {
+		_, _, err := parseTMJ([]byte(test.tmj))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q doesn't mention %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// TMX, Tiled's XML format. See http://doc.mapeditor.org/reference/tmx-map-format/
+
+type tmxMap struct {
+	Orientation string          `xml:"orientation,attr"`
+	Infinite    int             `xml:"infinite,attr"`
+	Width       int             `xml:"width,attr"`
+	Height      int             `xml:"height,attr"`
+	TileWidth   int             `xml:"tilewidth,attr"`
+	TileHeight  int             `xml:"tileheight,attr"`
+	Properties  []tmxProperty   `xml:"properties>property"`
+	Tilesets    []tmxTileset    `xml:"tileset"`
+	Layers      []tmxLayerGroup `xml:",any"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+
+	// Multi-line strings are saved as the element's text
+	Text string `xml:",chardata"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32        `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Margin     int           `xml:"margin,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Columns    int           `xml:"columns,attr"`
+	Image      tmxImage      `xml:"image"`
+	Tiles      []tmxTileInfo `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxTileInfo struct {
+	ID         int           `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+// tmxLayerGroup is any layer element: <layer>, <objectgroup>, <group> or
+// <imagelayer>. Keeping them in one list keeps the draw order.
+type tmxLayerGroup struct {
+	XMLName    xml.Name
+	Name       string          `xml:"name,attr"`
+	Width      int             `xml:"width,attr"`
+	Height     int             `xml:"height,attr"`
+	Visible    string          `xml:"visible,attr"`
+	OffsetX    float64         `xml:"offsetx,attr"`
+	OffsetY    float64         `xml:"offsety,attr"`
+	Properties []tmxProperty   `xml:"properties>property"`
+	Data       *tmxData        `xml:"data"`
+	Objects    []tmxObject     `xml:"object"`
+	Layers     []tmxLayerGroup `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string        `xml:"encoding,attr"`
+	Compression string        `xml:"compression,attr"`
+	Text        string        `xml:",chardata"`
+	Tiles       []tmxDataTile `xml:"tile"`
+}
+
+type tmxDataTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+func parseTMX(data []byte) (*Map, []tilesetRef, error) {
+	var doc tmxMap
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if err := checkMap(doc.Orientation, doc.Infinite != 0); err != nil {
+		return nil, nil, err
+	}
+
+	m := &Map{
+		Width:      doc.Width,
+		Height:     doc.Height,
+		TileWidth:  doc.TileWidth,
+		TileHeight: doc.TileHeight,
+		Properties: tmxProperties(doc.Properties),
+	}
+
+	var refs []tilesetRef
+	for _, t := range doc.Tilesets {
+		ref := tilesetRef{firstGID: t.FirstGID, source: t.Source}
+		if t.Source == "" {
+			ref.tileset = tmxToTileset(t)
+			ref.tileset.FirstGID = t.FirstGID
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := m.addTMXLayers(doc.Layers, 0, 0, true); err != nil {
+		return nil, nil, err
+	}
+	return m, refs, nil
+}
+
+// addTMXLayers flattens layers and groups into the map. Group offsets and
+// visibility apply to everything inside them.
+func (m *Map) addTMXLayers(layers []tmxLayerGroup, offsetX, offsetY float64, visible bool) error {
+	for _, l := range layers {
+		x, y := offsetX+l.OffsetX, offsetY+l.OffsetY
+		shown := visible && l.Visible != "0"
+
+		switch l.XMLName.Local {
+		case "layer":
+			if l.Data == nil {
+				return errors.New(fmt.Sprintf("tilemap: layer %s has no data", l.Name))
+			}
+			gids, err := tmxLayerData(l.Data, l.Width*l.Height)
+			if err != nil {
+				return err
+			}
+			m.Layers = append(m.Layers, &TileLayer{
+				Name:       l.Name,
+				Width:      l.Width,
+				Height:     l.Height,
+				Visible:    shown,
+				OffsetX:    int(x),
+				OffsetY:    int(y),
+				Properties: tmxProperties(l.Properties),
+				GIDs:       gids,
+			})
+
+		case "objectgroup":
+			group := &ObjectGroup{
+				Name:       l.Name,
+				Visible:    shown,
+				Properties: tmxProperties(l.Properties),
+			}
+			for _, o := range l.Objects {
+				typ := o.Type
+				if o.Class != "" {
+					typ = o.Class
+				}
+				group.Objects = append(group.Objects, newObject(o.ID, o.Name, typ,
+					o.X+x, o.Y+y, o.Width, o.Height, o.GID, tmxProperties(o.Properties)))
+			}
+			m.ObjectGroups = append(m.ObjectGroups, group)
+
+		case "group":
+			if err := m.addTMXLayers(l.Layers, x, y, shown); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tmxLayerData(d *tmxData, n int) ([]uint32, error) {
+	switch d.Encoding {
+	case "csv":
+		return decodeCSV(d.Text, n)
+	case "base64":
+		return decodeBase64(d.Text, d.Compression, n)
+	case "":
+		gids := make([]uint32, 0, n)
+		for _, t := range d.Tiles {
+			gids = append(gids, t.GID)
+		}
+		return checkLength(gids, n)
+	}
+	return nil, errors.New(fmt.Sprintf("tilemap: unknown layer encoding %s", d.Encoding))
+}
+
+func parseTSX(data []byte) (*Tileset, error) {
+	var doc tmxTileset
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return tmxToTileset(doc), nil
+}
+
+func tmxToTileset(t tmxTileset) *Tileset {
+	ts := &Tileset{
+		Name:           t.Name,
+		TileWidth:      t.TileWidth,
+		TileHeight:     t.TileHeight,
+		Spacing:        t.Spacing,
+		Margin:         t.Margin,
+		Columns:        t.Columns,
+		TileCount:      t.TileCount,
+		Image:          t.Image.Source,
+		TileProperties: make(map[int]Properties),
+	}
+	for _, tile := range t.Tiles {
+		if len(tile.Properties) > 0 {
+			ts.TileProperties[tile.ID] = tmxProperties(tile.Properties)
+		}
+	}
+	return ts
+}
+
+func tmxProperties(props []tmxProperty) Properties {
+	p := make(Properties)
+	for _, prop := range props {
+		if prop.Value != "" {
+			p[prop.Name] = prop.Value
+		} else {
+			p[prop.Name] = prop.Text
+		}
+	}
+	return p
+}
+
+// checkMap rejects maps this package can't draw.
+func checkMap(orientation string, infinite bool) error {
+	if orientation != "" && orientation != "orthogonal" {
+		return errors.New(fmt.Sprintf("tilemap: %s maps aren't supported", orientation))
+	}
+	if infinite {
+		return errors.New("tilemap: infinite maps aren't supported")
+	}
+	return nil
+}
+
+// newObject moves tile objects from their bottom left to top left corner.
+func newObject(id int, name, typ string, x, y, w, h float64, gid uint32, props Properties) *Object {
+	if gid != 0 {
+		y -= h
+	}
+	return &Object{
+		ID:         id,
+		Name:       name,
+		Type:       typ,
+		X:          x,
+		Y:          y,
+		Width:      w,
+		Height:     h,
+		GID:        gid,
+		Properties: props,
+	}
+}
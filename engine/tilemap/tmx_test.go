@@ -0,0 +1,357 @@
+package tilemap
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/paydro/gamedev/engine"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testTMX has one of everything: typed and multi-line properties, an inline
+// and an external tileset, a layer in each encoding, a hidden group with an
+// offset, and spawn points.
+func testTMX(t *testing.T) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <properties>
+  <property name="background" value="10,20,30"/>
+  <property name="gravity" type="float" value="9.5"/>
+  <property name="lives" type="int" value="3"/>
+  <property name="dark" type="bool" value="true"/>
+  <property name="intro">Once upon
+a time</property>
+ </properties>
+ <tileset firstgid="1" name="ground" tilewidth="16" tileheight="16" spacing="1" margin="2" tilecount="4" columns="2">
+  <image source="ground.png" width="36" height="36"/>
+  <tile id="0">
+   <properties>
+    <property name="solid" type="bool" value="true"/>
+   </properties>
+  </tile>
+  <tile id="3"/>
+ </tileset>
+ <tileset firstgid="5" source="tiles/props.tsx"/>
+ <layer id="1" name="csv" width="3" height="2">
+  <data encoding="csv">
+1,2,0,
+2147483651,1073741828,536870913
+</data>
+ </layer>
+ <layer id="2" name="zlib" width="3" height="2" visible="0">
+  <data encoding="base64" compression="zlib">
+   %s
+  </data>
+ </layer>
+ <group id="3" name="background" offsetx="10" offsety="20" visible="0">
+  <layer id="4" name="gzip" width="3" height="2" offsetx="1" offsety="2">
+   <properties>
+    <property name="parallax" type="float" value="0.5"/>
+   </properties>
+   <data encoding="base64" compression="gzip">%s</data>
+  </layer>
+  <group id="5" name="nested" offsetx="100">
+   <objectgroup id="6" name="decor">
+    <object id="1" name="cloud" x="0" y="0"/>
+   </objectgroup>
+  </group>
+ </group>
+ <layer id="7" name="xml" width="3" height="2">
+  <data>
+   <tile gid="1"/><tile gid="2"/><tile/>
+   <tile gid="2147483651"/><tile gid="1073741828"/><tile gid="536870913"/>
+  </data>
+ </layer>
+ <objectgroup id="8" name="spawns">
+  <object id="2" name="yoshi" type="player" x="32" y="48">
+   <point/>
+  </object>
+  <object id="3" name="shyguy" class="enemy" x="64" y="48" width="16" height="16" gid="5">
+   <properties>
+    <property name="speed" type="int" value="40"/>
+   </properties>
+  </object>
+  <object id="4" name="shyguy" type="ignored" class="enemy" x="80" y="48" width="16" height="16"/>
+ </objectgroup>
+</map>
+`, encodeGIDs(t, "zlib", testGIDs), encodeGIDs(t, "gzip", testGIDs))
+}
+
+const testTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="props" tilewidth="16" tileheight="32" tilecount="2" columns="2">
+ <image source="props.png" width="32" height="32"/>
+ <tile id="1">
+  <properties>
+   <property name="oneway" type="bool" value="true"/>
+  </properties>
+ </tile>
+</tileset>
+`
+
+func TestParseTMX(t *testing.T) {
+	m, refs, err := parseTMX([]byte(testTMX(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.TileHeight != 16 {
+		t.Errorf("Map is %dx%d of %dx%d tiles, expected 3x2 of 16x16", m.Width, m.Height, m.TileWidth, m.TileHeight)
+	}
+
+	props := Properties{
+		"background": "10,20,30",
+		"gravity":    "9.5",
+		"lives":      "3",
+		"dark":       "true",
+		"intro":      "Once upon\na time",
+	}
+	if !reflect.DeepEqual(m.Properties, props) {
+		t.Errorf("Map properties are %v, expected %v", m.Properties, props)
+	}
+	if m.Properties.Float("gravity") != 9.5 || m.Properties.Int("lives") != 3 || !m.Properties.Bool("dark") {
+		t.Errorf("Typed properties read as %v, %v, %v", m.Properties.Float("gravity"), m.Properties.Int("lives"), m.Properties.Bool("dark"))
+	}
+
+	if len(refs) != 2 {
+		t.Fatalf("Got %d tilesets, expected 2", len(refs))
+	}
+	ts := refs[0].tileset
+	if ts == nil || refs[0].source != "" {
+		t.Fatalf("First tileset should be inline, got %+v", refs[0])
+	}
+	if ts.FirstGID != 1 || ts.Name != "ground" || ts.Image != "ground.png" || ts.Spacing != 1 || ts.Margin != 2 || ts.Columns != 2 || ts.TileCount != 4 {
+		t.Errorf("Inline tileset is %+v", ts)
+	}
+	tileProps := map[int]Properties{0: {"solid": "true"}}
+	if !reflect.DeepEqual(ts.TileProperties, tileProps) {
+		t.Errorf("Tile properties are %v, expected %v", ts.TileProperties, tileProps)
+	}
+	if refs[1].tileset != nil || refs[1].source != "tiles/props.tsx" || refs[1].firstGID != 5 {
+		t.Errorf("Second tileset should be tiles/props.tsx at 5, got %+v", refs[1])
+	}
+
+	layers := []struct {
+		name             string
+		visible          bool
+		offsetX, offsetY int
+	}{
+		{"csv", true, 0, 0},
+		{"zlib", false, 0, 0},
+		{"gzip", false, 11, 22},
+		{"xml", true, 0, 0},
+	}
+	if len(m.Layers) != len(layers) {
+		t.Fatalf("Got %d layers, expected %d", len(m.Layers), len(layers))
+	}
+	for i, want := range layers {
+		l := m.Layers[i]
+		if l.Name != want.name || l.Visible != want.visible || l.OffsetX != want.offsetX || l.OffsetY != want.offsetY {
+			t.Errorf("Layer %d is %s visible %v at %d,%d, expected %+v", i, l.Name, l.Visible, l.OffsetX, l.OffsetY, want)
+		}
+		if l.Width != 3 || l.Height != 2 || !reflect.DeepEqual(l.GIDs, testGIDs) {
+			t.Errorf("%s: %dx%d %v, expected 3x2 %v", l.Name, l.Width, l.Height, l.GIDs, testGIDs)
+		}
+	}
+	if p := m.Layers[2].Properties.Float("parallax"); p != 0.5 {
+		t.Errorf("gzip layer parallax is %v, expected 0.5", p)
+	}
+
+	if len(m.ObjectGroups) != 2 {
+		t.Fatalf("Got %d object groups, expected 2", len(m.ObjectGroups))
+	}
+	decor := m.ObjectGroups[0]
+	if decor.Name != "decor" || decor.Visible {
+		t.Errorf("Group %s visible %v, expected decor hidden by its parent", decor.Name, decor.Visible)
+	}
+	if o := decor.Objects[0]; o.X != 110 || o.Y != 20 {
+		t.Errorf("cloud is at %v,%v, expected both groups' offsets 110,20", o.X, o.Y)
+	}
+	checkSpawns(t, m)
+}
+
+// checkSpawns checks the spawns layer of testTMX and testTMJ.
+func checkSpawns(t *testing.T, m *Map) {
+	x, y, ok := m.SpawnPoint("yoshi")
+	if !ok || x != 32 || y != 48 {
+		t.Errorf("yoshi spawns at %v,%v (%v), expected 32,48", x, y, ok)
+	}
+	if _, _, ok := m.SpawnPoint("bowser"); ok {
+		t.Errorf("Found a spawn point for bowser")
+	}
+
+	enemies := m.ObjectsOfType("enemy")
+	if len(enemies) != 2 {
+		t.Fatalf("Got %d enemies, expected 2", len(enemies))
+	}
+	// Tile objects are moved from their bottom left to their top left
+	shyguy := enemies[0]
+	if shyguy.ID != 3 || shyguy.X != 64 || shyguy.Y != 32 || shyguy.GID != 5 {
+		t.Errorf("Tile object is %+v, expected id 3 at 64,32 with gid 5", shyguy)
+	}
+	if shyguy.Properties.Int("speed") != 40 {
+		t.Errorf("shyguy speed is %v, expected 40", shyguy.Properties["speed"])
+	}
+	if rect := enemies[1]; rect.ID != 4 || rect.X != 80 || rect.Y != 48 {
+		t.Errorf("Rectangle object is %+v, expected id 4 at 80,48", rect)
+	}
+	if players := m.ObjectsOfType("player"); len(players) != 1 || players[0].Name != "yoshi" {
+		t.Errorf("Players are %v, expected yoshi", players)
+	}
+}
+
+func TestParseTMXErrors(t *testing.T) {
+	layer := func(data string) string {
+		return `<map orientation="orthogonal" width="2" height="1"><layer name="bad" width="2" height="1">` + data + `</layer></map>`
+	}
+	tests := []struct {
+		name string
+		tmx  string
+		err  string
+	}{
+		{"malformed", `<map width="2"><layer>`, "XML syntax error"},
+		{"isometric", `<map orientation="isometric"/>`, "isometric maps"},
+		{"infinite", `<map orientation="orthogonal" infinite="1"/>`, "infinite maps"},
+		{"no data", layer(``), "layer bad has no data"},
+		{"short csv", layer(`<data encoding="csv">1</data>`), "layer has 1 tiles, expected 2"},
+		{"bad csv", layer(`<data encoding="csv">1,x</data>`), "invalid syntax"},
+		{"short base64", layer(`<data encoding="base64">` + encodeGIDs(t, "", []uint32{1}) + `</data>`), "bad layer data"},
+		{"bad compression", layer(`<data encoding="base64" compression="zstd">AAAA</data>`), "zstd compressed layers"},
+		{"bad encoding", layer(`<data encoding="hex">0102</data>`), "unknown layer encoding hex"},
+		{"short xml", layer(`<data><tile gid="1"/></data>`), "layer has 1 tiles, expected 2"},
+		{"in a group", `<map><group><group><layer name="bad" width="2" height="1"><data encoding="csv">1</data></layer></group></group></map>`, "layer has 1 tiles"},
+	}
+
+	for _, test := range tests {
+		_, _, err := parseTMX([]byte(test.tmx))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q doesn't mention %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestFlipFlags(t *testing.T) {
+	m, refs, err := parseTMX([]byte(testTMX(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	external := &Tileset{FirstGID: refs[1].firstGID, TileProperties: map[int]Properties{1: {"oneway": "true"}}}
+	m.Tilesets = []*Tileset{refs[0].tileset, external}
+
+	l, _ := m.Layer("csv")
+	tests := []struct {
+		x, y int
+		tile uint32
+	}{
+		{0, 0, 1}, {1, 0, 2}, {2, 0, 0},
+		{0, 1, 3}, {1, 1, 4}, {2, 1, 1},
+		{-1, 0, 0}, {3, 0, 0}, {0, 2, 0},
+	}
+	for _, test := range tests {
+		if tile := l.Tile(test.x, test.y); tile != test.tile {
+			t.Errorf("Tile at %d,%d is %d, expected %d", test.x, test.y, tile, test.tile)
+		}
+	}
+
+	// The flags don't get in the way of finding the tileset or properties
+	flipped := l.GIDs[5]
+	if ts, ok := m.Tileset(flipped); !ok || ts.Name != "ground" {
+		t.Errorf("Flipped gid %x is in tileset %v, expected ground", flipped, ts)
+	}
+	if !m.TileProperties(flipped).Bool("solid") {
+		t.Errorf("Flipped gid %x lost its solid property", flipped)
+	}
+	if !m.TileProperties(6 | flippedHorizontally).Bool("oneway") {
+		t.Errorf("Flipped gid 6 has no oneway property from the external tileset")
+	}
+	if _, ok := m.Tileset(flippedHorizontally); ok {
+		t.Errorf("A flipped empty cell has a tileset")
+	}
+}
+
+func TestLoadTileset(t *testing.T) {
+	assets := engine.NewAssets(nil)
+	assets.SearchPaths = nil
+	assets.FS = fstest.MapFS{
+		"maps/tiles/props.tsx": {Data: []byte(testTSX)},
+		"maps/tiles/props.tsj": {Data: []byte(testTSJ)},
+		"maps/tiles/props.txt": {Data: []byte(testTSX)},
+		"maps/tiles/bad.tsx":   {Data: []byte(`<tileset name="bad"`)},
+	}
+
+	for _, file := range []string{"maps/tiles/props.tsx", "maps/tiles/props.tsj"} {
+		ts, err := loadTileset(assets, file)
+		if err != nil {
+			t.Errorf("%s: %s", file, err)
+			continue
+		}
+		// The image is relative to the tileset, not the map
+		if ts.Name != "props" || ts.Image != "maps/tiles/props.png" || ts.TileWidth != 16 || ts.TileHeight != 32 || ts.Columns != 2 {
+			t.Errorf("%s: tileset is %+v", file, ts)
+		}
+		if !ts.TileProperties[1].Bool("oneway") || len(ts.TileProperties) != 1 {
+			t.Errorf("%s: tile properties are %v", file, ts.TileProperties)
+		}
+	}
+
+	for _, file := range []string{"maps/tiles/props.txt", "maps/tiles/bad.tsx", "maps/tiles/missing.tsx"} {
+		if _, err := loadTileset(assets, file); err == nil {
+			t.Errorf("%s: expected an error", file)
+		}
+	}
+}
+
+func TestLoadExternalTilesets(t *testing.T) {
+	w, err := engine.NewHeadlessWindow("tilemap", 64, 64, 60)
+	if err != nil {
+		t.Skip("No SDL to load textures with.", err)
+	}
+	defer w.Cleanup()
+
+	png := pngData(t, 36, 36)
+	w.Assets.SearchPaths = nil
+	w.Assets.FS = fstest.MapFS{
+		"maps/level.tmx":       {Data: []byte(testTMX(t))},
+		"maps/ground.png":      {Data: png},
+		"maps/tiles/props.tsx": {Data: []byte(testTSX)},
+		"maps/tiles/props.png": {Data: png},
+	}
+
+	m, err := Load(w.Assets, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Release(w.Assets)
+
+	if len(m.Tilesets) != 2 {
+		t.Fatalf("Got %d tilesets, expected 2", len(m.Tilesets))
+	}
+	props := m.Tilesets[1]
+	if props.Name != "props" || props.FirstGID != 5 || props.Sheet == nil {
+		t.Errorf("External tileset is %+v, expected props at 5 with a sheet", props)
+	}
+	for gid, name := range map[uint32]string{1: "ground", 4: "ground", 5: "props", 6: "props"} {
+		if ts, ok := m.Tileset(gid); !ok || ts.Name != name {
+			t.Errorf("gid %d is in tileset %v, expected %s", gid, ts, name)
+		}
+	}
+	if w.Assets.Refs("maps/tiles/props.png") != 1 {
+		t.Errorf("props.png has %d references, expected 1", w.Assets.Refs("maps/tiles/props.png"))
+	}
+}
+
+// pngData is a blank w x h PNG.
+func pngData(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
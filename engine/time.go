@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TimeSource is where Clock and Sprite get the time from. Swap in a
+// ManualTime to step frames deterministically without SDL or a display.
+type TimeSource interface {
+	// Ticks is the number of ms since the source started.
+	Ticks() uint32
+
+	// Delay waits ms milliseconds.
+	Delay(ms uint32)
+}
+
+// SDLTime is the real clock, backed by SDL_GetTicks() and SDL_Delay().
+type SDLTime struct{}
+
+func (SDLTime) Ticks() uint32 {
+	return sdl.GetTicks()
+}
+
+func (SDLTime) Delay(ms uint32) {
+	sdl.Delay(ms)
+}
+
+// ManualTime is a fake clock that only moves when told to. Delay() returns
+// immediately and moves the clock forward, so a Clock capped at 60 FPS
+// advances exactly one frame per Tick().
+type ManualTime struct {
+	Now uint32
+}
+
+func NewManualTime() *ManualTime {
+	return &ManualTime{}
+}
+
+func (m *ManualTime) Ticks() uint32 {
+	return m.Now
+}
+
+func (m *ManualTime) Delay(ms uint32) {
+	m.Now += ms
+}
+
+// Advance moves the clock forward by ms, like time spent updating and
+// rendering a frame.
+func (m *ManualTime) Advance(ms uint32) {
+	m.Now += ms
+}
+
+// timeSource returns t, or the real SDL clock when t is nil so zero value
+// Clocks and Sprites keep working.
+func timeSource(t TimeSource) TimeSource {
+	if t == nil {
+		return SDLTime{}
+	}
+	return t
+}
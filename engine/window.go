@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paydro/gamedev/engine/input"
+	"github.com/veandco/go-sdl2/sdl"
+	"os"
+)
+
+// Pixel masks for a 32 bit RGBA surface with the bytes laid out R, G, B, A in
+// memory (SDL_PIXELFORMAT_ABGR8888 on little endian machines). That's the
+// same layout as image.RGBA.
+const (
+	rmask = 0x000000ff
+	gmask = 0x0000ff00
+	bmask = 0x00ff0000
+	amask = 0xff000000
+)
+
+// Window owns the SDL window and its renderer. Create one with NewWindow, or
+// NewHeadlessWindow when there is no display, and always defer Cleanup().
+type Window struct {
+	Title    string
+	Width    int
+	Height   int
+	FPS      int
+	window   *sdl.Window
+	renderer *sdl.Renderer
+
+	// Textures loaded for this window, destroyed by Cleanup()
+	Assets *Assets
+
+	// Headless windows have no real window. They render with the software
+	// renderer into surface.
+	Headless bool
+	surface  *sdl.Surface
+}
+
+func NewWindow(title string, width, height, fps int) (*Window, error) {
+	w := Window{
+		Title:  title,
+		Width:  width,
+		Height: height,
+		FPS:    fps,
+	}
+
+	ret := sdl.Init(sdl.INIT_EVERYTHING)
+	if ret < 0 {
+		return nil, errors.New(fmt.Sprintf("Failed to init SDL: %d\n", ret))
+	}
+
+	var window *sdl.Window
+	window = sdl.CreateWindow(
+		w.Title,
+		sdl.WINDOWPOS_UNDEFINED,
+		sdl.WINDOWPOS_UNDEFINED,
+		w.Width,
+		w.Height,
+		sdl.WINDOW_SHOWN)
+
+	if window == nil {
+		return nil, errors.New(fmt.Sprintf("Failed to create window: %s", sdl.GetError()))
+	}
+	w.window = window
+
+	var renderer *sdl.Renderer
+	renderer = sdl.CreateRenderer(w.window, -1, 0)
+	if renderer == nil {
+		return nil, errors.New(fmt.Sprintf("Failed to create renderer: %s", sdl.GetError()))
+	}
+	w.renderer = renderer
+	w.Assets = NewAssets(w.renderer)
+
+	return &w, nil
+}
+
+// NewHeadlessWindow works on machines without a display, like build boxes.
+// SDL is started with the dummy video driver (unless SDL_VIDEODRIVER is
+// already set, e.g. to "offscreen") and everything is drawn by the software
+// renderer into an in-memory surface. Textures, events and the game loop
+// behave the same as with NewWindow.
+func NewHeadlessWindow(title string, width, height, fps int) (*Window, error) {
+	w := Window{
+		Title:    title,
+		Width:    width,
+		Height:   height,
+		FPS:      fps,
+		Headless: true,
+	}
+
+	if os.Getenv("SDL_VIDEODRIVER") == "" {
+		os.Setenv("SDL_VIDEODRIVER", "dummy")
+	}
+
+	ret := sdl.Init(sdl.INIT_EVERYTHING)
+	if ret < 0 {
+		return nil, errors.New(fmt.Sprintf("Failed to init SDL: %d\n", ret))
+	}
+
+	surface := sdl.CreateRGBSurface(0, w.Width, w.Height, 32, rmask, gmask, bmask, amask)
+	if surface == nil {
+		return nil, errors.New(fmt.Sprintf("Failed to create surface: %s", sdl.GetError()))
+	}
+	w.surface = surface
+
+	renderer := sdl.CreateSoftwareRenderer(w.surface)
+	if renderer == nil {
+		return nil, errors.New(fmt.Sprintf("Failed to create renderer: %s", sdl.GetError()))
+	}
+	w.renderer = renderer
+	w.Assets = NewAssets(w.renderer)
+
+	return &w, nil
+}
+
+// Renderer is the renderer every texture for this window must be loaded with
+// and drawn to.
+func (w *Window) Renderer() *sdl.Renderer {
+	return w.renderer
+}
+
+func (w *Window) Cleanup() {
+	// Textures belong to the renderer, they go first
+	if w.Assets != nil {
+		w.Assets.DestroyAll()
+	}
+
+	if w.renderer != nil {
+		// Forget its camera, a new renderer can get the same address
+		SetCamera(w.renderer, nil)
+		w.renderer.Destroy()
+	}
+
+	if w.window != nil {
+		w.window.Destroy()
+	}
+
+	if w.surface != nil {
+		w.surface.Free()
+	}
+
+	// Games don't have to close their Actions' controllers themselves
+	input.CloseControllers()
+
+	sdl.Quit()
+}
@@ -1,75 +1,63 @@
+// Prints every SDL event the window gets, decoded by input.Inspector.
+//
+//	go run events.go
+//	go run events.go -json -types key,mouse > events.jsonl
+//
+// game009 and game011 have the same inspector, F12 turns it on.
 package main
 
 import (
+	"flag"
+	"github.com/paydro/gamedev/engine"
+	"github.com/paydro/gamedev/engine/input"
 	"github.com/veandco/go-sdl2/sdl"
-	"fmt"
+	"log"
 	"os"
+	"strings"
 )
 
-var winTitle string = "Go-SDL2 Events"
-var winWidth, winHeight int = 800, 600
+var asJSON = flag.Bool("json", false, "print JSON lines instead of text")
+var types = flag.String("types", "", "comma separated event types or categories to print (key, mouse, MOUSEMOTION ...), all when empty")
 
-type Timer struct {
-	LastTick int
-	FPS int
-}
+// events implements engine.Game, the loop's inspector does the printing
+type events struct{}
 
-func (t *Timer) Tick() {
-	if t.LastTick != nil
+func (events) HandleEvent(event sdl.Event) bool {
+	_, quit := event.(*sdl.QuitEvent)
+	return !quit
 }
 
+func (events) Update(dt float64) {}
 
-func main() {
-	var window *sdl.Window
-	var renderer *sdl.Renderer
-	var event sdl.Event
-	var running bool
-
-	window = sdl.CreateWindow(winTitle, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-			winWidth, winHeight, sdl.WINDOW_SHOWN)
-	if window == nil {
-		fmt.Fprintf(os.Stderr, "Failed to create window: %s\n", sdl.GetError());
-		os.Exit(1);
-	}
-
-	renderer = sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
-	if renderer == nil {
-		fmt.Fprintf(os.Stderr, "Failed to create renderer: %s\n", sdl.GetError());
-		os.Exit(2);
+func (events) Draw(r *sdl.Renderer, alpha float64) error {
+	if err := engine.SetDrawColor(r, 0, 0, 0, 255); err != nil {
+		return err
 	}
+	return engine.Clear(r)
+}
 
-	running = true
-	for running {
-		time := sdl.GetTicks()
-		fmt.Println("Tickets: %f", time)
-		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			switch t := event.(type) {
-			case *sdl.QuitEvent:
-				running = false
-			case *sdl.MouseMotionEvent:
-				fmt.Printf("[%d ms] MouseMotion\ttype:%d\tid:%d\tx:%d\ty:%d\txrel:%d\tyrel:%d\n",
-					   t.Timestamp, t.Type, t.Which, t.X, t.Y, t.XRel, t.YRel)
-			case *sdl.MouseButtonEvent:
-				fmt.Printf("[%d ms] MouseButton\ttype:%d\tid:%d\tx:%d\ty:%d\tbutton:%d\tstate:%d\n",
-					   t.Timestamp, t.Type, t.Which, t.X, t.Y, t.Button, t.State)
-			case *sdl.MouseWheelEvent:
-				fmt.Printf("[%d ms] MouseWheel\ttype:%d\tid:%d\tx:%d\ty:%d\n",
-					   t.Timestamp, t.Type, t.Which, t.X, t.Y)
-			case *sdl.KeyDownEvent:
-				fmt.Printf("[%d ms] KeyboardDown\ttype:%d\tsym:%c\tmodifiers:%d\tstate:%d\trepeat:%d\n",
-					   t.Timestamp, t.Type, t.Keysym.Sym, t.Keysym.Mod, t.State, t.Repeat)
-			case *sdl.KeyUpEvent:
-				if t.Keysym.Sym == 'a' {
-					fmt.Printf("You hit 'a'\n")
-				}
-				fmt.Printf("[%d ms] Keyboard\ttype:%d\tsym:%c\tmodifiers:%d\tstate:%d\trepeat:%d\n",
-					   t.Timestamp, t.Type, t.Keysym.Sym, t.Keysym.Mod, t.State, t.Repeat)
+func main() {
+	flag.Parse()
 
-			}
+	w, err := engine.NewWindow("Go-SDL2 Events", 800, 600, 60)
+	if err != nil {
+		log.Fatalln("Could not create window.", err)
+	}
+	defer w.Cleanup()
+
+	loop := engine.NewLoop(60, w.FPS)
+	inspector := input.NewInspector(os.Stdout)
+	inspector.Enabled = true
+	inspector.JSON = *asJSON
+	if *types != "" {
+		if err := inspector.Filter(strings.Split(*types, ",")...); err != nil {
+			log.Println(err)
+			return
 		}
 	}
+	loop.Inspector = inspector
 
-	renderer.Destroy()
-	window.Destroy()
+	if err := loop.Run(w, events{}); err != nil {
+		log.Println("Game loop failed.", err)
+	}
 }
-
@@ -3,6 +3,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/paydro/gamedev/engine/input"
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
 	"os"
@@ -84,6 +85,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	inspector := input.NewInspector(os.Stdout)
+	if err := inspector.Filter("quit", "mouse", "KEYUP"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up the event inspector: %s", err)
+		os.Exit(1)
+	}
+
 	var event sdl.Event
 	var running bool = true
 	for running {
@@ -91,19 +98,9 @@ func main() {
 
 		// Process events
 		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			switch t := event.(type) {
-			case *sdl.QuitEvent:
-				running = false
-			case *sdl.MouseMotionEvent:
-				fmt.Printf("[%d ms] MouseMotion\ttype:%d\tid:%d\tx:%d\ty:%d\txrel:%d\tyrel:%d\n", t.Timestamp, t.Type, t.Which, t.X, t.Y, t.XRel, t.YRel)
-			case *sdl.MouseButtonEvent:
-				fmt.Printf("[%d ms] MouseButton\ttype:%d\tid:%d\tx:%d\ty:%d\tbutton:%d\tstate:%d\n", t.Timestamp, t.Type, t.Which, t.X, t.Y, t.Button, t.State)
-				running = false
-			case *sdl.MouseWheelEvent:
-				fmt.Printf("[%d ms] MouseWheel\ttype:%d\tid:%d\tx:%d\ty:%d\n", t.Timestamp, t.Type, t.Which, t.X, t.Y)
-				running = false
-			case *sdl.KeyUpEvent:
-				fmt.Printf("[%d ms] Keyboard\ttype:%d\tsym:%c\tmodifiers:%d\tstate:%d\trepeat:%d\n", t.Timestamp, t.Type, t.Keysym.Sym, t.Keysym.Mod, t.State, t.Repeat)
+			inspector.Print(event)
+			switch event.(type) {
+			case *sdl.QuitEvent, *sdl.MouseButtonEvent, *sdl.MouseWheelEvent, *sdl.KeyUpEvent:
 				running = false
 			}
 		}
@@ -9,6 +9,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"github.com/paydro/gamedev/engine/input"
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_image"
 	"log"
@@ -238,49 +239,9 @@ func (p *Protagonist) Draw(r *sdl.Renderer) {
 	r.Copy(p.Texture, &sourceRect, &targetRect)
 }
 
-type ModifierKey uint16
-
-func (m ModifierKey) String() string {
-	var s string
-	switch m {
-	case sdl.KMOD_NONE:
-		s = "KMOD_NONE"
-	case sdl.KMOD_LSHIFT:
-		s = "KMOD_LSHIFT"
-	case sdl.KMOD_RSHIFT:
-		s = "KMOD_RSHIFT"
-	case sdl.KMOD_LCTRL:
-		s = "KMOD_LCTRL"
-	case sdl.KMOD_RCTRL:
-		s = "KMOD_RCTRL"
-	case sdl.KMOD_LALT:
-		s = "KMOD_LALT"
-	case sdl.KMOD_RALT:
-		s = "KMOD_RALT"
-	case sdl.KMOD_LGUI:
-		s = "KMOD_LGUI"
-	case sdl.KMOD_RGUI:
-		s = "KMOD_RGUI"
-	case sdl.KMOD_NUM:
-		s = "KMOD_NUM"
-	case sdl.KMOD_CAPS:
-		s = "KMOD_CAPS"
-	case sdl.KMOD_MODE:
-		s = "KMOD_MODE"
-	case sdl.KMOD_CTRL:
-		s = "KMOD_CTRL"
-	case sdl.KMOD_SHIFT:
-		s = "KMOD_SHIFT"
-	case sdl.KMOD_ALT:
-		s = "KMOD_ALT"
-	case sdl.KMOD_GUI:
-		s = "KMOD_GUI"
-	case sdl.KMOD_RESERVED:
-		s = "KMOD_RESERVED"
-	}
-
-	return s
-}
+// quit is GUI + q (COMMAND+q on Macs), by the letter on the key and
+// whatever the lock keys are
+var quit = input.MustParseKeyChord("Gui+Q")
 
 func main() {
 
@@ -318,7 +279,7 @@ func main() {
 				// log.Printf("KeyDownEvent: %+v", t)
 				// log.Printf(" * Scancode: %s", sdl.GetScancodeName(t.Keysym.Scancode))
 				// log.Printf(" * Keycode: %s", sdl.GetKeyName(t.Keysym.Sym))
-				// log.Printf(" * Modifier: %s", ModifierKey(t.Keysym.Mod))
+				// log.Printf(" * Modifier: %s", input.ModifierKey(t.Keysym.Mod))
 				if t.Keysym.Scancode == sdl.SCANCODE_UP || t.Keysym.Scancode == sdl.SCANCODE_W {
 					yoshi.Direction = UP
 				} else if t.Keysym.Scancode == sdl.SCANCODE_DOWN || t.Keysym.Scancode == sdl.SCANCODE_S {
@@ -333,9 +294,9 @@ func main() {
 				// log.Printf("KeyUpEvent: %+v\n", t)
 				// log.Printf(" * Scancode: %s", sdl.GetScancodeName(t.Keysym.Scancode))
 				// log.Printf(" * Keycode: %s", sdl.GetKeyName(t.Keysym.Sym))
-				// log.Printf(" * Modifier: %s", ModifierKey(t.Keysym.Mod))
+				// log.Printf(" * Modifier: %s", input.ModifierKey(t.Keysym.Mod))
 
-				if t.Keysym.Sym == sdl.K_q && (t.Keysym.Mod == sdl.KMOD_LGUI || t.Keysym.Mod == sdl.KMOD_RGUI) {
+				if quit.Matches(t) {
 					log.Println("Quitting ...")
 					running = false
 				}
@@ -0,0 +1,309 @@
+// Game 009
+// * game008 rebuilt on top of the engine package. Window, Clock, texture
+//   loading and sprite animation all come from engine now.
+// * Fixed timestep game loop. Yoshi is updated 60 times a second whatever the
+//   frame rate, and drawn interpolated between updates.
+// * `-frames N` runs N frames in a headless window on a fake clock and exits.
+//   A smoke test that works without a display.
+// * `-dev` reloads the sprites when they change on disk.
+// * Yoshi faces the way he's going, the sheet is mirrored for "run-left".
+// * Frames and their timing come from yoshi_trans_animation.json (Aseprite
+//   format) instead of being hard-coded.
+// * An animation state machine picks Yoshi's clip from his direction and
+//   speed. He stands still when pushed against a wall.
+// * The world is bigger than the window. A camera follows Yoshi around a
+//   tiled background.
+// * `-record FILE` saves the input and frame times, `-replay FILE` plays them
+//   back. Yoshi goes the same way every time, handy for reproducing bugs.
+// * F12 prints the events the game gets (input.Inspector).
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/paydro/gamedev/engine"
+	"github.com/paydro/gamedev/engine/input"
+	"github.com/veandco/go-sdl2/sdl"
+	"log"
+	"os"
+)
+
+// World size in pixels
+const worldWidth, worldHeight = 2400, 1800
+
+type Protagonist struct {
+	*engine.Sprite
+	engine.Direction
+
+	// Movement speed for protagonist, pixels a second
+	MoveSpeed int
+
+	anim *engine.AnimStateMachine
+}
+
+func NewProtagonist(sheet *engine.SpriteSheet, clips []*engine.Clip) (*Protagonist, error) {
+	p := &Protagonist{
+		Sprite:    engine.NewSprite(sheet, 16.0),
+		Direction: engine.RIGHT,
+		MoveSpeed: 200,
+	}
+	p.DestX, p.DestY = 100, 100
+
+	for _, clip := range clips {
+		p.AddClip(clip)
+	}
+	run, ok := p.Clips["run"]
+	if !ok {
+		return nil, errors.New("Yoshi's sprite sheet has no \"run\" tag")
+	}
+	p.AddClip(run.Mirrored("run-left", sdl.FLIP_HORIZONTAL))
+	p.AddClip(engine.NewClip("idle", []int{0}, 1, engine.PlayLoop))
+
+	p.anim = engine.NewAnimStateMachine(p.Sprite, "run-right")
+	p.anim.AddState("run-right", "run")
+	p.anim.AddState("run-left", "run-left")
+	p.anim.AddState("idle", "idle")
+
+	moving := func(d engine.Direction) func(engine.AnimParams) bool {
+		return func(params engine.AnimParams) bool {
+			return params.Get("speed") > 0 && engine.Direction(params.Get("direction")) == d
+		}
+	}
+	p.anim.AddTransition(engine.AnimTransition{From: "*", To: "idle",
+		When: func(params engine.AnimParams) bool { return params.Get("speed") == 0 }})
+	p.anim.AddTransition(engine.AnimTransition{From: "*", To: "run-left", When: moving(engine.LEFT)})
+	p.anim.AddTransition(engine.AnimTransition{From: "*", To: "run-right", When: moving(engine.RIGHT)})
+
+	// Going up or down keeps facing the same way, unless starting from idle
+	p.anim.AddTransition(engine.AnimTransition{From: "idle", To: "run-right",
+		When: func(params engine.AnimParams) bool { return params.Get("speed") > 0 }})
+
+	if err := p.anim.Update(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *Protagonist) Update(dt float64, world sdl.Rect) {
+	p.SavePosition()
+	toMove := int32(float64(p.MoveSpeed) * dt)
+	startX, startY := p.DestX, p.DestY
+
+	switch p.Direction {
+	case engine.UP:
+		p.DestY -= toMove
+	case engine.DOWN:
+		p.DestY += toMove
+	case engine.RIGHT:
+		p.DestX += toMove
+	case engine.LEFT:
+		p.DestX -= toMove
+	}
+
+	// World edge collision
+	if p.DestX < world.X {
+		p.DestX = world.X
+	}
+	if p.DestX+int32(p.Width) > world.X+world.W {
+		p.DestX = world.X + world.W - int32(p.Width)
+	}
+	if p.DestY < world.Y {
+		p.DestY = world.Y
+	}
+	if p.DestY+int32(p.Height) > world.Y+world.H {
+		p.DestY = world.Y + world.H - int32(p.Height)
+	}
+
+	moved := float64(abs(p.DestX-startX) + abs(p.DestY-startY))
+	p.anim.Params.Set("speed", moved/dt)
+	p.anim.Params.Set("direction", float64(p.Direction))
+	if err := p.anim.Update(); err != nil {
+		log.Println(err)
+	}
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// game implements engine.Game
+type game struct {
+	world      sdl.Rect
+	camera     *engine.Camera
+	background *sdl.Texture
+	yoshi      *Protagonist
+}
+
+func (g *game) HandleEvent(event sdl.Event) bool {
+	switch t := event.(type) {
+	case *sdl.QuitEvent:
+		return false
+
+	case *sdl.KeyDownEvent:
+		switch t.Keysym.Scancode {
+		case sdl.SCANCODE_UP, sdl.SCANCODE_W:
+			g.yoshi.Direction = engine.UP
+		case sdl.SCANCODE_DOWN, sdl.SCANCODE_S:
+			g.yoshi.Direction = engine.DOWN
+		case sdl.SCANCODE_LEFT, sdl.SCANCODE_A:
+			g.yoshi.Direction = engine.LEFT
+		case sdl.SCANCODE_RIGHT, sdl.SCANCODE_D:
+			g.yoshi.Direction = engine.RIGHT
+		}
+
+	case *sdl.KeyUpEvent:
+		if quit.Matches(t) {
+			log.Println("Quitting ...")
+			return false
+		}
+	}
+	return true
+}
+
+func (g *game) Update(dt float64) {
+	g.yoshi.Update(dt, g.world)
+	g.camera.Update(dt)
+}
+
+// Checksum makes game implement engine.Checksummer, replays check Yoshi
+// and the camera end up where they were when recording.
+func (g *game) Checksum() uint64 {
+	y := g.yoshi
+	return engine.HashValues(float64(y.DestX), float64(y.DestY), float64(y.Direction), g.camera.X, g.camera.Y)
+}
+
+func (g *game) Draw(r *sdl.Renderer, alpha float64) error {
+	if err := engine.SetDrawColor(r, 205, 205, 205, 255); err != nil {
+		return err
+	}
+	if err := engine.Clear(r); err != nil {
+		return err
+	}
+
+	engine.SetCamera(r, g.camera)
+	defer engine.SetCamera(r, nil)
+
+	if err := g.drawBackground(r); err != nil {
+		return err
+	}
+	return g.yoshi.Draw(r, alpha)
+}
+
+// drawBackground tiles the background over the part of the world in view.
+func (g *game) drawBackground(r *sdl.Renderer) error {
+	tileW, tileH, err := engine.QueryTexture(g.background)
+	if err != nil {
+		return err
+	}
+
+	view := g.camera.Visible()
+	for y := int(view.Y) / tileH * tileH; y < int(view.Y+view.H); y += tileH {
+		for x := int(view.X) / tileW * tileW; x < int(view.X+view.W); x += tileW {
+			if err := engine.RenderTexture(g.background, r, x, y, tileW, tileH); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var frames = flag.Int("frames", 0, "run headless for this many frames then exit")
+var record = flag.String("record", "", "record the input to this file")
+var replay = flag.String("replay", "", "play back input recorded with -record instead of the keyboard's")
+var dev = flag.Bool("dev", false, "reload textures when their files change")
+
+// Quit with GUI + q (COMMAND+q on Macs)
+var quit = input.MustParseKeyChord("Gui+Q")
+
+func main() {
+	flag.Parse()
+
+	var w *engine.Window
+	var err error
+	if *frames > 0 {
+		w, err = engine.NewHeadlessWindow("Game 009", 800, 600, 60)
+	} else {
+		w, err = engine.NewWindow("Game 009", 800, 600, 60)
+	}
+	if err != nil {
+		log.Fatalln("Could not create window.", err)
+	}
+	defer w.Cleanup()
+
+	// The sprites live in the repo root. Works when run from the root or
+	// from game009/
+	w.Assets.SearchPaths = append(w.Assets.SearchPaths, "..")
+	w.Assets.HotReload = *dev
+
+	sheet, clips, err := w.Assets.SpriteSheet("yoshi_trans_animation.json")
+	if err != nil {
+		log.Println("Failed to load yoshi sprite sheet.", err)
+		return
+	}
+	defer w.Assets.Release(sheet.Texture)
+
+	yoshi, err := NewProtagonist(sheet, clips)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	background, err := w.Assets.Texture("background.png")
+	if err != nil {
+		log.Println("Failed to load background.", err)
+		return
+	}
+	defer w.Assets.Release(background)
+
+	camera := engine.NewCamera(w.Width, w.Height)
+	camera.SetBounds(0, 0, worldWidth, worldHeight)
+	camera.Target = yoshi
+	camera.DeadZoneW, camera.DeadZoneH = 200, 150
+	camera.FollowSpeed = 5
+
+	g := &game{
+		world:      sdl.Rect{W: worldWidth, H: worldHeight},
+		camera:     camera,
+		background: background,
+		yoshi:      yoshi,
+	}
+	loop := engine.NewLoop(60, w.FPS)
+	// On the loop's time Yoshi animates the same when replayed
+	g.yoshi.Time = loop.Time
+	loop.Inspector = input.NewInspector(os.Stderr)
+
+	if w.Headless {
+		fake := engine.NewManualTime()
+		loop.Clock.Time = fake
+		loop.Frames = *frames
+	}
+
+	if *replay != "" {
+		loop.Replay, err = engine.LoadReplay(*replay)
+		if err != nil {
+			log.Println("Could not load replay.", err)
+			return
+		}
+	}
+	if *record != "" {
+		loop.Recorder, err = engine.NewRecorder(*record, loop)
+		if err != nil {
+			log.Println("Could not record.", err)
+			return
+		}
+		defer func() {
+			if err := loop.Recorder.Close(); err != nil {
+				log.Println("Could not save recording.", err)
+			}
+		}()
+	}
+
+	if err := loop.Run(w, g); err != nil {
+		// Not log.Fatal, deferred cleanup still has to run
+		log.Println("Game loop failed.", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Game 010
+// * Texture atlas. dude.png, the happy face and Yoshi's sheet are packed into
+//   one texture when the game loads, everything is drawn from it.
+// * `-dump-atlas DIR` writes the atlas and its JSON index to DIR.
+// * `-frames N` runs N frames headless and exits, like game009.
+// * Draws go through a RenderQueue, sorted by layer and texture. `-stats`
+//   logs draw calls and texture switches once a second.
+
+package main
+
+import (
+	"flag"
+	"github.com/paydro/gamedev/engine"
+	"github.com/paydro/gamedev/engine/input"
+	"github.com/veandco/go-sdl2/sdl"
+	"log"
+)
+
+var frames = flag.Int("frames", 0, "run headless for this many frames then exit")
+var dumpAtlas = flag.String("dump-atlas", "", "write the atlas PNG and JSON index to this directory")
+var stats = flag.Bool("stats", false, "log render stats once a second")
+
+// Quit with GUI + q (COMMAND+q on Macs)
+var quit = input.MustParseKeyChord("Gui+Q")
+
+// Layers, drawn bottom to top
+const (
+	backgroundLayer = iota
+	entityLayer
+)
+
+// game implements engine.Game
+type game struct {
+	dude, face *engine.TextureRegion
+	yoshi      *engine.Sprite
+	queue      *engine.RenderQueue
+	frame      int
+}
+
+func (g *game) HandleEvent(event sdl.Event) bool {
+	switch t := event.(type) {
+	case *sdl.QuitEvent:
+		return false
+	case *sdl.KeyUpEvent:
+		if quit.Matches(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *game) Update(dt float64) {}
+
+func (g *game) Draw(r *sdl.Renderer, alpha float64) error {
+	if err := engine.SetDrawColor(r, 205, 205, 205, 255); err != nil {
+		return err
+	}
+	if err := engine.Clear(r); err != nil {
+		return err
+	}
+
+	// Submitted top layer first, the queue sorts it out
+	g.yoshi.Submit(g.queue, entityLayer, 0, alpha)
+	engine.SubmitRegion(g.dude, g.queue, 300, 100, int(g.dude.Rect.W), int(g.dude.Rect.H), entityLayer, 0)
+	engine.SubmitRegion(g.face, g.queue, 50, 50, int(g.face.Rect.W), int(g.face.Rect.H), backgroundLayer, 0)
+
+	if err := g.queue.Flush(r); err != nil {
+		return err
+	}
+
+	g.frame += 1
+	if *stats && g.frame%60 == 0 {
+		s := g.queue.Stats()
+		log.Printf("draw calls: %d, texture switches: %d", s.DrawCalls, s.TextureSwitches)
+	}
+	return nil
+}
+
+func main() {
+	flag.Parse()
+
+	var w *engine.Window
+	var err error
+	if *frames > 0 {
+		w, err = engine.NewHeadlessWindow("Game 010", 640, 480, 60)
+	} else {
+		w, err = engine.NewWindow("Game 010", 640, 480, 60)
+	}
+	if err != nil {
+		log.Fatalln("Could not create window.", err)
+	}
+	defer w.Cleanup()
+	w.Assets.SearchPaths = append(w.Assets.SearchPaths, "..")
+
+	atlas := engine.NewAtlas(512)
+	for _, path := range []string{"dude.png", "happy_face_transparent.png", "yoshi_trans_animation.png"} {
+		if err := atlas.AddFile(w.Assets, path); err != nil {
+			log.Println("Failed to load image.", err)
+			return
+		}
+	}
+	if err := atlas.Build(w.Renderer()); err != nil {
+		log.Println("Failed to build atlas.", err)
+		return
+	}
+	defer atlas.Destroy()
+
+	if *dumpAtlas != "" {
+		if err := atlas.Dump(*dumpAtlas); err != nil {
+			log.Println("Failed to dump atlas.", err)
+		}
+	}
+
+	g := &game{queue: engine.NewRenderQueue()}
+	g.dude, _ = atlas.Region("dude.png")
+	g.face, _ = atlas.Region("happy_face_transparent.png")
+	yoshiRegion, _ := atlas.Region("yoshi_trans_animation.png")
+	yoshiSheet, err := yoshiRegion.SpriteSheet(64, 64)
+	if err != nil {
+		log.Println("Failed to cut yoshi's frames.", err)
+		return
+	}
+	g.yoshi = engine.NewSprite(yoshiSheet, 16.0)
+	g.yoshi.DestX, g.yoshi.DestY = 400, 300
+
+	loop := engine.NewLoop(60, w.FPS)
+	if w.Headless {
+		fake := engine.NewManualTime()
+		loop.Clock.Time = fake
+		g.yoshi.Time = fake
+		loop.Frames = *frames
+	}
+
+	if err := loop.Run(w, g); err != nil {
+		// Not log.Fatal, deferred cleanup still has to run
+		log.Println("Rendering failed.", err)
+	}
+}
@@ -0,0 +1,294 @@
+// Game 011
+// * Tiled maps. level1.tmx is drawn with the tilemap package, only the tiles
+//   the camera sees.
+// * Yoshi starts at the "yoshi" object of the map's spawns layer.
+// * Platformer controls: left/right (or A/D) to run, space or up to jump,
+//   down to drop through the thin platforms. Yoshi stands on the ground,
+//   walks up the hill's slopes and is stopped by walls, all from the tile
+//   properties in tiles.tsx.
+// * Controls are input actions. Bindings are saved in input.json (`-input`
+//   to pick another file), edit it or press F1 then a key to rebind jump.
+// * Game controllers work too, plug them in any time: d-pad or left stick to
+//   run (the stick runs slower when only tilted a little), A to jump.
+// * `-frames N` runs N frames headless and exits, like game009.
+// * `-record FILE` saves the input and frame times, `-replay FILE` plays them
+//   back. Yoshi goes the same way every time, handy for reproducing bugs.
+// * F12 prints the events the game gets (input.Inspector).
+
+package main
+
+import (
+	"flag"
+	"github.com/paydro/gamedev/engine"
+	"github.com/paydro/gamedev/engine/input"
+	"github.com/paydro/gamedev/engine/tilemap"
+	"github.com/veandco/go-sdl2/sdl"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var frames = flag.Int("frames", 0, "run headless for this many frames then exit")
+var record = flag.String("record", "", "record the input to this file")
+var replay = flag.String("replay", "", "play back input recorded with -record instead of the keyboard's")
+var inputPath = flag.String("input", "input.json", "file the key bindings are loaded from and saved to")
+
+var defaultBindings = input.Config{
+	"move_left":   {"Left", "A", "Pad dpleft", "Pad leftx-"},
+	"move_right":  {"Right", "D", "Pad dpright", "Pad leftx+"},
+	"jump":        {"Space", "Up", "W", "Pad a"},
+	"drop":        {"Down", "S", "Pad dpdown", "Pad lefty+"},
+	"quit":        {"Gui+Q", "Escape"},
+	"rebind_jump": {"F1"},
+}
+
+// game implements engine.Game
+type game struct {
+	actions    *input.Actions
+	level      *tilemap.Map
+	collider   *tilemap.Collider
+	camera     *engine.Camera
+	yoshi      *engine.Sprite
+	body       *tilemap.Body
+	background sdl.Color
+	moveSpeed  float64
+	jumpSpeed  float64
+	gravity    float64
+}
+
+// Yoshi's frames have some empty space around him, the body he collides
+// with is smaller than the sprite.
+const bodyOffsetX, bodyOffsetY = 12, 8
+
+func (g *game) HandleEvent(event sdl.Event) bool {
+	if _, ok := event.(*sdl.QuitEvent); ok {
+		return false
+	}
+
+	g.actions.HandleEvent(event)
+	if g.actions.Pressed("quit") {
+		return false
+	}
+	if g.actions.Pressed("rebind_jump") {
+		log.Println("Press the new jump key ...")
+		g.actions.Capture(g.rebindJump)
+	}
+	return true
+}
+
+// rebindJump replaces the first jump binding with b, or adds it when jump
+// has none, and saves the bindings.
+func (g *game) rebindJump(b input.Binding) {
+	var old input.Binding
+	bindings := g.actions.Bindings("jump")
+	if len(bindings) > 0 {
+		old = bindings[0]
+	}
+	if err := g.actions.Rebind("jump", old, b); err != nil {
+		log.Println("Could not rebind jump.", err)
+		return
+	}
+	if len(bindings) > 0 {
+		log.Printf("Jump is now %s instead of %s", b, old)
+	} else {
+		log.Printf("Jump is now %s", b)
+	}
+	if err := g.actions.Save(*inputPath); err != nil {
+		log.Println("Could not save bindings.", err)
+	}
+}
+
+func (g *game) Update(dt float64) {
+	g.yoshi.SavePosition()
+
+	b := g.body
+	b.VX = g.actions.Axis("move_left", "move_right") * g.moveSpeed
+	if b.VX < 0 {
+		g.yoshi.Play("run-left")
+	} else if b.VX > 0 {
+		g.yoshi.Play("run")
+	}
+	if g.actions.Down("jump") && b.OnFloor {
+		b.VY = -g.jumpSpeed
+	}
+	b.DropThrough = g.actions.Down("drop")
+	g.actions.EndUpdate()
+
+	b.VY += g.gravity * dt
+	g.collider.Move(b, dt)
+
+	g.yoshi.DestX = int32(b.X) - bodyOffsetX
+	g.yoshi.DestY = int32(b.Y) - bodyOffsetY
+
+	g.camera.Update(dt)
+}
+
+// Checksum makes game implement engine.Checksummer, replays check Yoshi
+// and the camera end up where they were when recording.
+func (g *game) Checksum() uint64 {
+	b := g.body
+	return engine.HashValues(b.X, b.Y, b.VX, b.VY, g.camera.X, g.camera.Y)
+}
+
+func (g *game) Draw(r *sdl.Renderer, alpha float64) error {
+	bg := g.background
+	if err := engine.SetDrawColor(r, bg.R, bg.G, bg.B, 255); err != nil {
+		return err
+	}
+	if err := engine.Clear(r); err != nil {
+		return err
+	}
+
+	engine.SetCamera(r, g.camera)
+	defer engine.SetCamera(r, nil)
+
+	if err := g.level.Draw(r); err != nil {
+		return err
+	}
+	return g.yoshi.Draw(r, alpha)
+}
+
+// parseColor reads an "r,g,b" map property, gray when it's missing.
+func parseColor(s string) sdl.Color {
+	c := sdl.Color{R: 205, G: 205, B: 205, A: 255}
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return c
+	}
+	channels := []*uint8{&c.R, &c.G, &c.B}
+	for i, part := range parts {
+		if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			*channels[i] = uint8(n)
+		}
+	}
+	return c
+}
+
+func main() {
+	flag.Parse()
+
+	var w *engine.Window
+	var err error
+	if *frames > 0 {
+		w, err = engine.NewHeadlessWindow("Game 011", 800, 600, 60)
+	} else {
+		w, err = engine.NewWindow("Game 011", 800, 600, 60)
+	}
+	if err != nil {
+		log.Fatalln("Could not create window.", err)
+	}
+	defer w.Cleanup()
+	w.Assets.SearchPaths = append(w.Assets.SearchPaths, "..")
+
+	level, err := tilemap.Load(w.Assets, "level1.tmx")
+	if err != nil {
+		log.Println("Failed to load level.", err)
+		return
+	}
+	defer level.Release(w.Assets)
+
+	sheet, clips, err := w.Assets.SpriteSheet("yoshi_trans_animation.json")
+	if err != nil {
+		log.Println("Failed to load yoshi sprite sheet.", err)
+		return
+	}
+	defer w.Assets.Release(sheet.Texture)
+
+	yoshi := engine.NewSprite(sheet, 16.0)
+	for _, clip := range clips {
+		yoshi.AddClip(clip)
+	}
+	if run, ok := yoshi.Clips["run"]; ok {
+		yoshi.AddClip(run.Mirrored("run-left", sdl.FLIP_HORIZONTAL))
+		yoshi.Play("run")
+	}
+
+	x, y, ok := level.SpawnPoint("yoshi")
+	if !ok {
+		log.Println("level1.tmx has no yoshi spawn point")
+		return
+	}
+	yoshi.DestX, yoshi.DestY = int32(x), int32(y)
+	body := &tilemap.Body{
+		X:      x + bodyOffsetX,
+		Y:      y + bodyOffsetY,
+		Width:  float64(yoshi.Width) - 2*bodyOffsetX,
+		Height: float64(yoshi.Height) - bodyOffsetY,
+	}
+
+	ground, ok := level.Layer("ground")
+	if !ok {
+		log.Println("level1.tmx has no ground layer")
+		return
+	}
+
+	bounds := level.Bounds()
+	camera := engine.NewCamera(w.Width, w.Height)
+	camera.SetBounds(0, 0, int(bounds.W), int(bounds.H))
+	camera.Target = yoshi
+	camera.DeadZoneW, camera.DeadZoneH = 160, 120
+
+	actions, err := input.NewActions(defaultBindings)
+	if err != nil {
+		log.Println("Bad default bindings.", err)
+		return
+	}
+	if err := actions.Load(*inputPath); err != nil {
+		log.Println("Could not load bindings, using the defaults.", err)
+	}
+	actions.Controllers.OnAdded = func(c *input.Controller) {
+		log.Printf("Controller %d plugged in: %s", c.ID, c.Name)
+	}
+	actions.Controllers.OnRemoved = func(c *input.Controller) {
+		log.Printf("Controller %d unplugged", c.ID)
+	}
+
+	g := &game{
+		actions:    actions,
+		level:      level,
+		collider:   tilemap.NewCollider(level, ground),
+		camera:     camera,
+		yoshi:      yoshi,
+		body:       body,
+		background: parseColor(level.Properties.String("background")),
+		moveSpeed:  200,
+		jumpSpeed:  650,
+		gravity:    1800,
+	}
+
+	loop := engine.NewLoop(60, w.FPS)
+	// On the loop's time Yoshi animates the same when replayed
+	yoshi.Time = loop.Time
+	loop.Inspector = input.NewInspector(os.Stderr)
+	if w.Headless {
+		fake := engine.NewManualTime()
+		loop.Clock.Time = fake
+		loop.Frames = *frames
+	}
+
+	if *replay != "" {
+		loop.Replay, err = engine.LoadReplay(*replay)
+		if err != nil {
+			log.Println("Could not load replay.", err)
+			return
+		}
+	}
+	if *record != "" {
+		loop.Recorder, err = engine.NewRecorder(*record, loop)
+		if err != nil {
+			log.Println("Could not record.", err)
+			return
+		}
+		defer func() {
+			if err := loop.Recorder.Close(); err != nil {
+				log.Println("Could not save recording.", err)
+			}
+		}()
+	}
+
+	if err := loop.Run(w, g); err != nil {
+		// Not log.Fatal, deferred cleanup still has to run
+		log.Println("Game loop failed.", err)
+	}
+}
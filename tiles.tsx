@@ -0,0 +1,36 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="tiles" tilewidth="32" tileheight="32" tilecount="6" columns="6">
+ <image source="tiles.png" width="192" height="32"/>
+ <tile id="0">
+  <properties>
+   <property name="solid" type="bool" value="true"/>
+  </properties>
+ </tile>
+ <tile id="1">
+  <properties>
+   <property name="solid" type="bool" value="true"/>
+  </properties>
+ </tile>
+ <tile id="2">
+  <properties>
+   <property name="oneway" type="bool" value="true"/>
+  </properties>
+ </tile>
+ <tile id="3">
+  <properties>
+   <property name="solid" type="bool" value="true"/>
+  </properties>
+ </tile>
+ <tile id="4">
+  <properties>
+   <property name="slope_left" type="int" value="0"/>
+   <property name="slope_right" type="int" value="32"/>
+  </properties>
+ </tile>
+ <tile id="5">
+  <properties>
+   <property name="slope_left" type="int" value="32"/>
+   <property name="slope_right" type="int" value="0"/>
+  </properties>
+ </tile>
+</tileset>